@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"strings"
+	"sync/atomic"
 
 	"github.com/AvicennaJr/Nuru/token"
 )
@@ -70,6 +71,10 @@ func (ls *LetStatement) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
+	// Builtin remembers the builtin this name turned out to be, so the
+	// evaluator can skip looking it up again. Only the evaluator sets it,
+	// and it is atomic since one program may be run by several goroutines.
+	Builtin atomic.Value
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -239,6 +244,7 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression // can be Identifier or FunctionLiteral
 	Arguments []Expression
+	Named     []*NamedArgument // salimu(jina: "Asha"), always after Arguments
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -250,6 +256,9 @@ func (ce *CallExpression) String() string {
 	for _, a := range ce.Arguments {
 		args = append(args, a.String())
 	}
+	for _, a := range ce.Named {
+		args = append(args, a.String())
+	}
 
 	out.WriteString(ce.Function.String())
 	out.WriteString("(")
@@ -259,6 +268,18 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+type NamedArgument struct {
+	Token token.Token // the name's token
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string {
+	return na.Name.String() + ": " + na.Value.String()
+}
+
 type StringLiteral struct {
 	Token token.Token
 	Value string
@@ -291,9 +312,10 @@ func (al *ArrayLiteral) String() string {
 }
 
 type IndexExpression struct {
-	Token token.Token
-	Left  Expression
-	Index Expression
+	Token    token.Token
+	Left     Expression
+	Index    Expression
+	Optional bool // arr?[i], which is tupu when arr is tupu
 }
 
 func (ie *IndexExpression) expressionNode()      {}
@@ -303,6 +325,9 @@ func (ie *IndexExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(ie.Left.String())
+	if ie.Optional {
+		out.WriteString("?")
+	}
 	out.WriteString("[")
 	out.WriteString(ie.Index.String())
 	out.WriteString("])")
@@ -310,9 +335,84 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+type SliceExpression struct {
+	Token token.Token // the '[' token
+	Left  Expression
+	Start Expression // nil when left out, eg a[:2]
+	End   Expression // nil when left out, eg a[2:]
+	Step  Expression // nil when left out, eg a[1:3]
+	// a?[1:3], which is tupu when a is tupu
+	Optional bool
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	if se.Optional {
+		out.WriteString("?")
+	}
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	if se.Step != nil {
+		out.WriteString(":")
+		out.WriteString(se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+type Comprehension struct {
+	Token     token.Token // the '[' or '{' token
+	Key       Expression  // only set for dict comprehensions
+	Value     Expression
+	KeyName   string // "" when only one loop variable is given
+	ValueName string
+	Iterable  Expression
+	Condition Expression // nil when there is no 'kama' filter
+}
+
+func (c *Comprehension) expressionNode()      {}
+func (c *Comprehension) TokenLiteral() string { return c.Token.Literal }
+func (c *Comprehension) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(c.Token.Literal)
+	if c.Key != nil {
+		out.WriteString(c.Key.String() + ": ")
+	}
+	out.WriteString(c.Value.String())
+	out.WriteString(" kwa ")
+	if c.KeyName != "" {
+		out.WriteString(c.KeyName + ", ")
+	}
+	out.WriteString(c.ValueName + " ktk " + c.Iterable.String())
+	if c.Condition != nil {
+		out.WriteString(" kama " + c.Condition.String())
+	}
+	if c.Key != nil {
+		out.WriteString("}")
+	} else {
+		out.WriteString("]")
+	}
+
+	return out.String()
+}
+
 type DictLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	Keys  []Expression // the keys in the order they were written
 }
 
 func (dl *DictLiteral) expressionNode()      {}
@@ -320,8 +420,8 @@ func (dl *DictLiteral) TokenLiteral() string { return dl.Token.Literal }
 func (dl *DictLiteral) String() string {
 	var out bytes.Buffer
 	pairs := []string{}
-	for key, value := range dl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
+	for _, key := range dl.Keys {
+		pairs = append(pairs, key.String()+":"+dl.Pairs[key].String())
 	}
 
 	out.WriteString("(")
@@ -368,6 +468,34 @@ func (we *WhileExpression) String() string {
 	return out.String()
 }
 
+// BlockExpression is a group of statements that gives a value, eg
+// kizuizi { fanya x = 2; toa x * x }
+type BlockExpression struct {
+	Token token.Token // the 'kizuizi' token
+	Block *BlockStatement
+}
+
+func (be *BlockExpression) expressionNode()      {}
+func (be *BlockExpression) TokenLiteral() string { return be.Token.Literal }
+func (be *BlockExpression) String() string {
+	return be.Token.Literal + " " + be.Block.String()
+}
+
+// YieldStatement leaves the nearest kizuizi with a value
+type YieldStatement struct {
+	Token token.Token // the 'toa' token
+	Value Expression
+}
+
+func (ys *YieldStatement) statementNode()       {}
+func (ys *YieldStatement) TokenLiteral() string { return ys.Token.Literal }
+func (ys *YieldStatement) String() string {
+	if ys.Value == nil {
+		return ys.TokenLiteral() + ";"
+	}
+	return ys.TokenLiteral() + " " + ys.Value.String() + ";"
+}
+
 type Null struct {
 	Token token.Token
 }
@@ -435,6 +563,7 @@ type ForIn struct {
 	Token    token.Token
 	Key      string
 	Value    string
+	Pattern  []string // kwa [a, b] ktk jozi, the names each element is spread into
 	Iterable Expression
 	Block    *BlockStatement
 }
@@ -448,7 +577,11 @@ func (fi *ForIn) String() string {
 	if fi.Key != "" {
 		out.WriteString(fi.Key + ", ")
 	}
-	out.WriteString(fi.Value + " ")
+	if fi.Pattern != nil {
+		out.WriteString("[" + strings.Join(fi.Pattern, ", ") + "] ")
+	} else {
+		out.WriteString(fi.Value + " ")
+	}
 	out.WriteString("ktk ")
 	out.WriteString(fi.Iterable.String() + " {\n")
 	out.WriteString("\t" + fi.Block.String())
@@ -458,10 +591,12 @@ func (fi *ForIn) String() string {
 }
 
 type CaseExpression struct {
-	Token   token.Token
-	Default bool
-	Expr    []Expression
-	Block   *BlockStatement
+	Token    token.Token
+	Default  bool
+	Expr     []Expression
+	Operator string     // ikiwa < 10, compares the switch value with Expr[0]
+	Guard    Expression // ikiwa kama x > 5
+	Block    *BlockStatement
 }
 
 func (ce *CaseExpression) expressionNode()      {}
@@ -474,6 +609,13 @@ func (ce *CaseExpression) String() string {
 	} else {
 		out.WriteString("ikiwa ")
 
+		if ce.Guard != nil {
+			out.WriteString("kama " + ce.Guard.String())
+		}
+		if ce.Operator != "" {
+			out.WriteString(ce.Operator + " ")
+		}
+
 		tmp := []string{}
 		for _, exp := range ce.Expr {
 			tmp = append(tmp, exp.String())
@@ -507,3 +649,24 @@ func (se *SwitchExpression) String() string {
 
 	return out.String()
 }
+
+type PropertyExpression struct {
+	Token    token.Token // the '.' or '?.' token
+	Object   Expression
+	Property *Identifier
+	Optional bool // obj?.field, which is tupu when obj is tupu
+}
+
+func (pe *PropertyExpression) expressionNode()      {}
+func (pe *PropertyExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PropertyExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(pe.Object.String())
+	out.WriteString(pe.Token.Literal)
+	out.WriteString(pe.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
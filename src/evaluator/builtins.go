@@ -2,21 +2,58 @@ package evaluator
 
 import (
 	"bufio"
+	"crypto/md5"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math"
+	"math/bits"
+	"math/rand"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/AvicennaJr/Nuru/object"
 )
 
+// output is where andika and the jaza prompt write to
+var output io.Writer = os.Stdout
+
+// random backs every builtin that needs randomness, so pandaMbegu can make
+// a whole program reproducible
+var random = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// SetOutput sends everything a program prints to w instead of stdout
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// logOutput is where logInfo, logOnyo and logKosa write to
+var logOutput io.Writer = os.Stderr
+
+// SetLogOutput sends the log messages of a program to w instead of stderr
+func SetLogOutput(w io.Writer) {
+	logOutput = w
+}
+
 var builtins = map[string]*object.Builtin{
 	"idadi": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Hoja hazilingani, tunahitaji=1, tumepewa=%d", len(args))
+				return newError("Mstari %d: Hoja hazilingani, tunahitaji=1, tumepewa=%d", line, len(args))
 			}
 
 			switch arg := args[0].(type) {
@@ -24,15 +61,17 @@ var builtins = map[string]*object.Builtin{
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
 				return &object.Integer{Value: int64(len(arg.Value))}
+			case *object.Set:
+				return &object.Integer{Value: int64(arg.Len())}
 			default:
-				return newError("Samahani, hii function haitumiki na %s", args[0].Type())
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
 			}
 		},
 	},
 	"jumla": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Hoja hazilingani, tunahitaji=1, tumepewa=%d", len(args))
+				return newError("Mstari %d: Hoja hazilingani, tunahitaji=1, tumepewa=%d", line, len(args))
 			}
 
 			switch arg := args[0].(type) {
@@ -42,7 +81,7 @@ var builtins = map[string]*object.Builtin{
 				for _,num := range arg.Elements {
 				   
                    if num.Type() != object.INTEGER_OBJ && num.Type() != object.FLOAT_OBJ{
-					  return newError("Samahani namba tu zinahitajika")
+					  return newError("Mstari %d: Samahani namba tu zinahitajika", line)
 				   }else{
 					if num.Type() == object.INTEGER_OBJ{
 						no , _ := strconv.Atoi(num.Inspect())
@@ -64,17 +103,17 @@ var builtins = map[string]*object.Builtin{
 				return &object.Float {Value: float64(sums)}
 			
 			default:
-				return newError("Samahani, hii function haitumiki na %s", args[0].Type())
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
 			}
 		},
 	},
 	"yamwisho": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Samahani, tunahitaji Hoja moja tu, wewe umeweka %d", len(args))
+				return newError("Mstari %d: Samahani, tunahitaji Hoja moja tu, wewe umeweka %d", line, len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("Samahani, hii function haitumiki na %s", args[0].Type())
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
@@ -87,12 +126,12 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"sukuma": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) != 2 {
-				return newError("Samahani, tunahitaji Hoja 2, wewe umeweka %d", len(args))
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
 			}
 			if args[0].Type() != object.ARRAY_OBJ {
-				return newError("Samahani, hii function haitumiki na %s", args[0].Type())
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
 			}
 
 			arr := args[0].(*object.Array)
@@ -106,55 +145,2468 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 	"jaza": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 
 			if len(args) > 1 {
-				return newError("Samahani, hii function inapokea hoja 0 au 1, wewe umeweka %d", len(args))
+				return newError("Mstari %d: Samahani, hii function inapokea hoja 0 au 1, wewe umeweka %d", line, len(args))
 			}
 
 			if len(args) > 0 && args[0].Type() != object.STRING_OBJ {
-				return newError(fmt.Sprintf(`Tafadhali tumia alama ya nukuu: "%s"`, args[0].Inspect()))
+				return newError(`Mstari %d: Tafadhali tumia alama ya nukuu: "%s"`, line, args[0].Inspect())
 			}
 			if len(args) == 1 {
 				prompt := args[0].(*object.String).Value
-				fmt.Fprint(os.Stdout, prompt)
+				fmt.Fprint(output, prompt)
 			}
 
 			buffer := bufio.NewReader(os.Stdin)
 
-			line, _, err := buffer.ReadLine()
+			input, _, err := buffer.ReadLine()
 			if err != nil && err != io.EOF {
-				return newError("Nimeshindwa kusoma uliyo yajaza")
+				return newError("Mstari %d: Nimeshindwa kusoma uliyo yajaza", line)
 			}
 
-			return &object.String{Value: string(line)}
+			return &object.String{Value: string(input)}
 		},
 	},
 	"andika": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) == 0 {
-				fmt.Println("")
+				fmt.Fprintln(output)
 			} else {
 				var arr []string
 				for _, arg := range args {
 					if arg == nil {
-						return newError("Hauwezi kufanya operesheni hii")
+						return newError("Mstari %d: Hauwezi kufanya operesheni hii", line)
 					}
 					arr = append(arr, arg.Inspect())
 				}
 				str := strings.Join(arr, " ")
-				print(str + "\n")
+				fmt.Fprintln(output, str)
 			}
 			return nil
 		},
 	},
 	"aina": {
-		Fn: func(args ...object.Object) object.Object {
+		Fn: func(line int, args ...object.Object) object.Object {
 			if len(args) != 1 {
-				return newError("Samahani, tunahitaji Hoja 1, wewe umeweka %d", len(args))
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
 			}
 
 			return &object.String{Value: string(args[0].Type())}
 		},
 	},
+	"kwaBinari": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return formatIntegerBase(line, args, 2)
+		},
+	},
+	"kwaOktali": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return formatIntegerBase(line, args, 8)
+		},
+	},
+	"kwaHex": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return formatIntegerBase(line, args, 16)
+		},
+	},
+	"kutokaMsingi": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			base, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, msingi lazima uwe NAMBA, sio %s", line, args[1].Type())
+			}
+			if base.Value < 2 || base.Value > 36 {
+				return newError("Mstari %d: Samahani, msingi lazima uwe kati ya 2 na 36, sio %d", line, base.Value)
+			}
+
+			value, err := strconv.ParseInt(strings.TrimSpace(str.Value), int(base.Value), 64)
+			if err != nil {
+				return newError("Mstari %d: Samahani, %q sio namba sahihi ya msingi %d", line, str.Value, base.Value)
+			}
+			return &object.Integer{Value: value}
+		},
+	},
+	"bana": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 3, wewe umeweka %d", line, len(args))
+			}
+			if x, ok := args[0].(*object.Integer); ok {
+				low, lok := args[1].(*object.Integer)
+				high, hok := args[2].(*object.Integer)
+				if lok && hok {
+					if low.Value > high.Value {
+						return newError("Mstari %d: Samahani, chini (%d) haiwezi kuzidi juu (%d)", line, low.Value, high.Value)
+					}
+					if x.Value < low.Value {
+						return low
+					}
+					if x.Value > high.Value {
+						return high
+					}
+					return x
+				}
+			}
+
+			var nums [3]float64
+			for i, arg := range args {
+				num, ok := numberToFloat(arg)
+				if !ok {
+					return newError("Mstari %d: Samahani, namba tu zinahitajika, sio %s", line, arg.Type())
+				}
+				nums[i] = num
+			}
+			x, low, high := nums[0], nums[1], nums[2]
+			if low > high {
+				return newError("Mstari %d: Samahani, chini (%s) haiwezi kuzidi juu (%s)", line, args[1].Inspect(), args[2].Inspect())
+			}
+			return &object.Float{Value: math.Min(math.Max(x, low), high)}
+		},
+	},
+	"kati": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 3, wewe umeweka %d", line, len(args))
+			}
+			a, aok := args[0].(*object.Integer)
+			b, bok := args[1].(*object.Integer)
+			t, tok := args[2].(*object.Integer)
+			if aok && bok && tok {
+				return &object.Integer{Value: a.Value + (b.Value-a.Value)*t.Value}
+			}
+
+			var nums [3]float64
+			for i, arg := range args {
+				num, ok := numberToFloat(arg)
+				if !ok {
+					return newError("Mstari %d: Samahani, namba tu zinahitajika, sio %s", line, arg.Type())
+				}
+				nums[i] = num
+			}
+			return &object.Float{Value: nums[0] + (nums[1]-nums[0])*nums[2]}
+		},
+	},
+	"linganishaBila": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			a, aok := args[0].(*object.String)
+			b, bok := args[1].(*object.String)
+			if !aok || !bok {
+				return newError("Mstari %d: Samahani, hii function inalinganisha NENO tu, sio %s na %s", line, args[0].Type(), args[1].Type())
+			}
+			return nativeBoolToBooleanObject(strings.EqualFold(a.Value, b.Value))
+		},
+	},
+	"mfululizo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka %d", line, len(args))
+			}
+			allIntegers := true
+			nums := []float64{0, 0, 1}
+			for i, arg := range args {
+				num, ok := numberToFloat(arg)
+				if !ok {
+					return newError("Mstari %d: Samahani, namba tu zinahitajika, sio %s", line, arg.Type())
+				}
+				if arg.Type() != object.INTEGER_OBJ {
+					allIntegers = false
+				}
+				nums[i] = num
+			}
+			start, stop, step := nums[0], nums[1], nums[2]
+			if step == 0 {
+				return newError("Mstari %d: Samahani, hatua haiwezi kuwa sifuri", line)
+			}
+
+			if allIntegers {
+				ints := []int64{0, 0, 1}
+				for i, arg := range args {
+					ints[i] = arg.(*object.Integer).Value
+				}
+				return integerRange(line, ints[0], ints[1], ints[2])
+			}
+
+			// Each value is computed as start + i*step instead of adding step
+			// repeatedly, so rounding errors don't pile up. The small tolerance
+			// keeps values that only differ from stop by rounding out of the result.
+			count := math.Ceil((stop-start)/step - 1e-9)
+			if count < 0 {
+				count = 0
+			}
+			if !(count <= maxRangeLength) {
+				return newError("Mstari %d: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu %d", line, maxRangeLength)
+			}
+			elements := make([]object.Object, int(count))
+			for i := range elements {
+				elements[i] = &object.Float{Value: start + float64(i)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+	"jaribuNambari": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka %d", line, len(args))
+			}
+			base := int64(10)
+			if len(args) == 3 {
+				b, ok := args[2].(*object.Integer)
+				if !ok {
+					return newError("Mstari %d: Samahani, msingi lazima uwe NAMBA, sio %s", line, args[2].Type())
+				}
+				if b.Value < 2 || b.Value > 36 {
+					return newError("Mstari %d: Samahani, msingi lazima uwe kati ya 2 na 36, sio %d", line, b.Value)
+				}
+				base = b.Value
+			}
+
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return args[1]
+			}
+			value, err := strconv.ParseInt(strings.TrimSpace(str.Value), int(base), 64)
+			if err != nil {
+				return args[1]
+			}
+			return &object.Integer{Value: value}
+		},
+	},
+	"nambari": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			switch arg := args[0].(type) {
+			case *object.Integer, *object.Float:
+				return arg
+			case *object.String:
+				str := strings.TrimSpace(arg.Value)
+				if value, err := strconv.ParseInt(str, 10, 64); err == nil {
+					return &object.Integer{Value: value}
+				}
+				if value, err := strconv.ParseFloat(str, 64); err == nil {
+					return &object.Float{Value: value}
+				}
+				return newError("Mstari %d: Samahani, %q sio namba", line, arg.Value)
+			default:
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+		},
+	},
+	"vipengele": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			dict, ok := args[0].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			pairs := make([]object.Object, 0, len(dict.Pairs))
+			for _, key := range dict.Keys() {
+				pair := dict.Pairs[key]
+				pairs = append(pairs, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+			}
+			return &object.Array{Elements: pairs}
+		},
+	},
+	"kutokaVipengele": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			for i, elem := range arr.Elements {
+				pair, ok := elem.(*object.Array)
+				if !ok || len(pair.Elements) != 2 {
+					return newError("Mstari %d: Samahani, kipengele %d sio jozi ya [key, thamani]: %s", line, i, elem.Inspect())
+				}
+				hashKey, ok := pair.Elements[0].(object.Hashable)
+				if !ok {
+					return newError("Mstari %d: Samahani, %s haitumiki kama key", line, pair.Elements[0].Type())
+				}
+				dict.Set(hashKey.HashKey(), object.DictPair{Key: pair.Elements[0], Value: pair.Elements[1]})
+			}
+			return dict
+		},
+	},
+	"pata": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			url, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, url lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			req, err := http.NewRequest(http.MethodGet, url.Value, nil)
+			if err != nil {
+				return newError("Mstari %d: Samahani, ombi limeshindikana: %s", line, err)
+			}
+			return doRequest(line, req)
+		},
+	},
+	"tuma": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka %d", line, len(args))
+			}
+			url, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, url lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			body, ok := args[1].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, mwili lazima uwe NENO, sio %s", line, args[1].Type())
+			}
+			req, err := http.NewRequest(http.MethodPost, url.Value, strings.NewReader(body.Value))
+			if err != nil {
+				return newError("Mstari %d: Samahani, ombi limeshindikana: %s", line, err)
+			}
+			if len(args) == 3 {
+				headers, ok := args[2].(*object.Dict)
+				if !ok {
+					return newError("Mstari %d: Samahani, vichwa lazima viwe KAMUSI, sio %s", line, args[2].Type())
+				}
+				for _, key := range headers.Keys() {
+					pair := headers.Pairs[key]
+					req.Header.Set(pair.Key.Inspect(), pair.Value.Inspect())
+				}
+			}
+			return doRequest(line, req)
+		},
+	},
+	"base64Simba": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, func(s string) (string, error) {
+				return base64.StdEncoding.EncodeToString([]byte(s)), nil
+			})
+		},
+	},
+	"base64Fungua": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, func(s string) (string, error) {
+				decoded, err := base64.StdEncoding.DecodeString(s)
+				return string(decoded), err
+			})
+		},
+	},
+	"urlSimba": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, func(s string) (string, error) {
+				return url.QueryEscape(s), nil
+			})
+		},
+	},
+	"urlFungua": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, url.QueryUnescape)
+		},
+	},
+	"sha256": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, func(s string) (string, error) {
+				sum := sha256.Sum256([]byte(s))
+				return hex.EncodeToString(sum[:]), nil
+			})
+		},
+	},
+	"md5": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertString(line, args, func(s string) (string, error) {
+				sum := md5.Sum([]byte(s))
+				return hex.EncodeToString(sum[:]), nil
+			})
+		},
+	},
+	"somaCsv": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			text, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			records, err := csv.NewReader(strings.NewReader(text.Value)).ReadAll()
+			if err != nil {
+				return newError("Mstari %d: Samahani, CSV si sahihi: %s", line, err)
+			}
+
+			rows := make([]object.Object, len(records))
+			for i, record := range records {
+				fields := make([]object.Object, len(record))
+				for j, field := range record {
+					fields[j] = &object.String{Value: field}
+				}
+				rows[i] = &object.Array{Elements: fields}
+			}
+			return &object.Array{Elements: rows}
+		},
+	},
+	"andikaCsv": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			rows, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			var out strings.Builder
+			w := csv.NewWriter(&out)
+			for i, row := range rows.Elements {
+				fields, ok := row.(*object.Array)
+				if !ok {
+					return newError("Mstari %d: Samahani, safu %d lazima iwe ORODHA, sio %s", line, i, row.Type())
+				}
+				record := make([]string, len(fields.Elements))
+				for j, field := range fields.Elements {
+					record[j] = field.Inspect()
+				}
+				if err := w.Write(record); err != nil {
+					return newError("Mstari %d: Samahani, imeshindikana kuandika CSV: %s", line, err)
+				}
+			}
+			w.Flush()
+			if err := w.Error(); err != nil {
+				return newError("Mstari %d: Samahani, imeshindikana kuandika CSV: %s", line, err)
+			}
+			return &object.String{Value: out.String()}
+		},
+	},
+	"orodhaDir": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			entries, err := os.ReadDir(path.Value)
+			if err != nil {
+				return newError("Mstari %d: Samahani, imeshindikana kusoma folda: %s", line, err)
+			}
+			names := make([]object.Object, len(entries))
+			for i, entry := range entries {
+				names[i] = &object.String{Value: entry.Name()}
+			}
+			return &object.Array{Elements: names}
+		},
+	},
+	"nipo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			_, err := os.Stat(path.Value)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return newError("Mstari %d: Samahani, imeshindikana kuangalia njia: %s", line, err)
+			}
+			return nativeBoolToBooleanObject(err == nil)
+		},
+	},
+	"unganishaNjia": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			parts := make([]string, len(args))
+			for i, arg := range args {
+				part, ok := arg.(*object.String)
+				if !ok {
+					return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, arg.Type())
+				}
+				parts[i] = part.Value
+			}
+			return &object.String{Value: filepath.Join(parts...)}
+		},
+	},
+	"fanyaDir": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			if err := os.MkdirAll(path.Value, 0755); err != nil {
+				return newError("Mstari %d: Samahani, imeshindikana kutengeneza folda: %s", line, err)
+			}
+			return NULL
+		},
+	},
+	"somaMistari": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			lines, err := object.OpenLines(path.Value)
+			if err != nil {
+				return newError("Mstari %d: Samahani, imeshindikana kufungua faili: %s", line, err)
+			}
+			return lines
+		},
+	},
+	"umboNamba": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1 hadi 3, wewe umeweka %d", line, len(args))
+			}
+			separator := ","
+			if len(args) > 1 {
+				sep, ok := args[1].(*object.String)
+				if !ok {
+					return newError("Mstari %d: Samahani, kitenganishi lazima kiwe NENO, sio %s", line, args[1].Type())
+				}
+				separator = sep.Value
+			}
+			precision := 2
+			if len(args) > 2 {
+				prec, ok := args[2].(*object.Integer)
+				if !ok || prec.Value < 0 {
+					return newError("Mstari %d: Samahani, usahihi lazima uwe NAMBA isiyo hasi, sio %s", line, args[2].Inspect())
+				}
+				precision = int(prec.Value)
+			}
+
+			var digits string
+			switch n := args[0].(type) {
+			case *object.Integer:
+				digits = strconv.FormatInt(n.Value, 10)
+			case *object.Float:
+				if math.IsNaN(n.Value) || math.IsInf(n.Value, 0) {
+					return &object.String{Value: n.Inspect()}
+				}
+				digits = strconv.FormatFloat(n.Value, 'f', precision, 64)
+			default:
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			return &object.String{Value: groupThousands(digits, separator)}
+		},
+	},
+	"tafuta": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return findSubstring(line, args, strings.Index)
+		},
+	},
+	"tafutaMwisho": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return findSubstring(line, args, strings.LastIndex)
+		},
+	},
+	"pataAu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 3, wewe umeweka %d", line, len(args))
+			}
+			dict, ok := args[0].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe KAMUSI, sio %s", line, args[0].Type())
+			}
+			key, ok := args[1].(object.Hashable)
+			if !ok {
+				return newError("Mstari %d: Samahani, %s haitumiki kama key", line, args[1].Type())
+			}
+			if pair, ok := dict.Pairs[key.HashKey()]; ok {
+				return pair.Value
+			}
+			return args[2]
+		},
+	},
+	"zungusha_orodha": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+			}
+			n, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe NAMBA, sio %s", line, args[1].Type())
+			}
+
+			length := int64(len(arr.Elements))
+			elements := make([]object.Object, 0, length)
+			if length == 0 {
+				return &object.Array{Elements: elements}
+			}
+			// a negative n rotates right, the modulo keeps it in range
+			shift := ((n.Value % length) + length) % length
+			elements = append(elements, arr.Elements[shift:]...)
+			elements = append(elements, arr.Elements[:shift]...)
+			return &object.Array{Elements: elements}
+		},
+	},
+	"sukumaMbele": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			newElements := make([]object.Object, len(arr.Elements)+1)
+			newElements[0] = args[1]
+			copy(newElements[1:], arr.Elements)
+			return &object.Array{Elements: newElements}
+		},
+	},
+	"toaMbele": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			if len(arr.Elements) == 0 {
+				return &object.Array{Elements: []object.Object{}}
+			}
+			newElements := make([]object.Object, len(arr.Elements)-1)
+			copy(newElements, arr.Elements[1:])
+			return &object.Array{Elements: newElements}
+		},
+	},
+	"karibuSawa": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka %d", line, len(args))
+			}
+			a, ok := numberToFloat(args[0])
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			b, ok := numberToFloat(args[1])
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[1].Type())
+			}
+			tolerance := 1e-9
+			if len(args) == 3 {
+				tolerance, ok = numberToFloat(args[2])
+				if !ok || tolerance < 0 {
+					return newError("Mstari %d: Samahani, uvumilivu lazima uwe namba isiyo hasi, sio %s", line, args[2].Inspect())
+				}
+			}
+			return nativeBoolToBooleanObject(math.Abs(a-b) <= tolerance)
+		},
+	},
+	"hesabuBiti": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return bitOperation(line, args, func(n uint64) int64 {
+				return int64(bits.OnesCount64(n))
+			})
+		},
+	},
+	"bitiYaJuu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return bitOperation(line, args, func(n uint64) int64 {
+				return int64(bits.Len64(n)) - 1
+			})
+		},
+	},
+	"badiliBaiti": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return bitOperation(line, args, func(n uint64) int64 {
+				return int64(bits.ReverseBytes64(n))
+			})
+		},
+	},
+	"funguo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return dictColumn(line, args, func(pair object.DictPair) object.Object { return pair.Key })
+		},
+	},
+	"thamani": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return dictColumn(line, args, func(pair object.DictPair) object.Object { return pair.Value })
+		},
+	},
+	"sombaNambari": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			var text strings.Builder
+			for i, elem := range arr.Elements {
+				var r rune
+				switch elem := elem.(type) {
+				case *object.String:
+					if utf8.RuneCountInString(elem.Value) != 1 {
+						return newError("Mstari %d: Samahani, kipengele %d lazima kiwe herufi moja, sio %q", line, i, elem.Value)
+					}
+					r, _ = utf8.DecodeRuneInString(elem.Value)
+				case *object.Integer:
+					if elem.Value < 0 || elem.Value > utf8.MaxRune {
+						return newError("Mstari %d: Samahani, kipengele %d sio herufi sahihi: %d", line, i, elem.Value)
+					}
+					r = rune(elem.Value)
+				default:
+					return newError("Mstari %d: Samahani, kipengele %d lazima kiwe NENO au NAMBA, sio %s", line, i, elem.Type())
+				}
+				if !strings.ContainsRune("0123456789+-.", r) {
+					return newError("Mstari %d: Samahani, kipengele %d sio sehemu ya namba: %q", line, i, string(r))
+				}
+				text.WriteRune(r)
+			}
+
+			if value, err := strconv.ParseInt(text.String(), 10, 64); err == nil {
+				return &object.Integer{Value: value}
+			}
+			if value, err := strconv.ParseFloat(text.String(), 64); err == nil {
+				return &object.Float{Value: value}
+			}
+			return newError("Mstari %d: Samahani, %q sio namba", line, text.String())
+		},
+	},
+	"niUtf8Sahihi": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertUtf8(line, args, func(s string) object.Object {
+				return nativeBoolToBooleanObject(utf8.ValidString(s))
+			})
+		},
+	},
+	"safishaUtf8": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return convertUtf8(line, args, func(s string) object.Object {
+				return &object.String{Value: strings.ToValidUTF8(s, string(utf8.RuneError))}
+			})
+		},
+	},
+	"pandaMbegu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			seed, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, mbegu lazima iwe NAMBA, sio %s", line, args[0].Type())
+			}
+			random.Seed(seed.Value)
+			return NULL
+		},
+	},
+	"changanya": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			elements := make([]object.Object, len(arr.Elements))
+			copy(elements, arr.Elements)
+			random.Shuffle(len(elements), func(i, j int) {
+				elements[i], elements[j] = elements[j], elements[i]
+			})
+			return &object.Array{Elements: elements}
+		},
+	},
+	"mjengo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 0, wewe umeweka %d", line, len(args))
+			}
+			return &object.Builder{}
+		},
+	},
+	"mjengoOrodha": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 0, wewe umeweka %d", line, len(args))
+			}
+			return &object.Builder{Array: true}
+		},
+	},
+	"kitambulisho": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 0, wewe umeweka %d", line, len(args))
+			}
+			var id [16]byte
+			if _, err := crand.Read(id[:]); err != nil {
+				return newError("Mstari %d: Samahani, imeshindikana kutengeneza kitambulisho: %s", line, err)
+			}
+			id[6] = id[6]&0x0f | 0x40 // version 4
+			id[8] = id[8]&0x3f | 0x80 // RFC 4122 variant
+			return &object.String{Value: fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])}
+		},
+	},
+	"pataNdani": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[1].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe ORODHA, sio %s", line, args[1].Type())
+			}
+
+			current := args[0]
+			for _, step := range path.Elements {
+				next, ok := pathStep(current, step)
+				if !ok {
+					return NULL
+				}
+				current = next
+			}
+			return current
+		},
+	},
+	"wekaNdani": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 3, wewe umeweka %d", line, len(args))
+			}
+			path, ok := args[1].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, njia lazima iwe ORODHA, sio %s", line, args[1].Type())
+			}
+			if len(path.Elements) == 0 {
+				return newError("Mstari %d: Samahani, njia haina kitu", line)
+			}
+
+			// the containers along the path are changed in place, only
+			// missing levels are created
+			current := args[0]
+			last := len(path.Elements) - 1
+			for i, step := range path.Elements[:last] {
+				next, ok := pathStep(current, step)
+				if _, isDict := current.(*object.Dict); isDict && (!ok || next == NULL) {
+					next = &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+					if err := setIndex(current, step, next, line); err != nil {
+						return err
+					}
+				} else if !ok {
+					// a missing array index or a step into something that is
+					// not a container, setIndex explains which
+					return setIndex(current, step, NULL, line)
+				}
+				switch next.(type) {
+				case *object.Dict, *object.Array:
+				default:
+					return newError("Mstari %d: Samahani, hatua %d ya njia (%s) ni %s, sio KAMUSI wala ORODHA", line, i, step.Inspect(), next.Type())
+				}
+				current = next
+			}
+			if err := setIndex(current, path.Elements[last], args[2], line); err != nil {
+				return err
+			}
+			return args[0]
+		},
+	},
+	"zungushaChini": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			return roundNumber(line, args[0], math.Floor)
+		},
+	},
+	"zungushaJuu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			return roundNumber(line, args[0], math.Ceil)
+		},
+	},
+	"zungushaKaribu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 && len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1 au 2, wewe umeweka %d", line, len(args))
+			}
+			if len(args) == 1 {
+				return roundNumber(line, args[0], math.Round)
+			}
+			if mode, ok := args[1].(*object.String); !ok || mode.Value != "benki" {
+				return newError(`Mstari %d: Samahani, njia ya kuzungusha inaweza kuwa "benki" tu, sio %s`, line, args[1].Inspect())
+			}
+			return roundNumber(line, args[0], math.RoundToEven)
+		},
+	},
+	"niNaN": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return checkNumber(line, args, math.IsNaN)
+		},
+	},
+	"niInf": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return checkNumber(line, args, func(x float64) bool { return math.IsInf(x, 0) })
+		},
+	},
+	"niKamili": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return checkNumber(line, args, func(x float64) bool {
+				return !math.IsInf(x, 0) && math.Trunc(x) == x
+			})
+		},
+	},
+	"kwaSeti": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			set := &object.Set{}
+			for i, elem := range arr.Elements {
+				hashable, ok := elem.(object.Hashable)
+				if !ok {
+					return newError("Mstari %d: Samahani, kipengele %d ni %s, hakiwezi kuwekwa kwenye seti", line, i, elem.Type())
+				}
+				set.Add(hashable)
+			}
+			return set
+		},
+	},
+	"kwaOrodha": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			set, ok := args[0].(*object.Set)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			return &object.Array{Elements: set.Elements()}
+		},
+	},
+	"logInfo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return writeLog(line, "INFO", args)
+		},
+	},
+	"logOnyo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return writeLog(line, "ONYO", args)
+		},
+	},
+	"logKosa": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return writeLog(line, "KOSA", args)
+		},
+	},
+	"unganishaKwaKina": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			a, ok := args[0].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			b, ok := args[1].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[1].Type())
+			}
+			merged, err := deepMerge(line, a, b)
+			if err != nil {
+				return err
+			}
+			return merged
+		},
+	},
+	"kipengee": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 3, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+			}
+			index, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Tafadhali tumia number, sio: %s", line, args[1].Type())
+			}
+			idx := index.Value
+			if idx < 0 {
+				idx += int64(len(arr.Elements))
+			}
+			if idx < 0 || idx >= int64(len(arr.Elements)) {
+				return args[2]
+			}
+			return arr.Elements[idx]
+		},
+	},
+	"urefuMkubwa": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return extremeLength(line, args, func(a, b int) bool { return a > b })
+		},
+	},
+	"urefuMdogo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return extremeLength(line, args, func(a, b int) bool { return a < b })
+		},
+	},
+	"keyYaKubwa": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return keyByValue(line, args, ">")
+		},
+	},
+	"keyYaNdogo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return keyByValue(line, args, "<")
+		},
+	},
+	"bapa": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			dict, ok := args[0].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			flat := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			if err := flattenDict(line, dict, "", flat, map[*object.Dict]bool{}); err != nil {
+				return err
+			}
+			return flat
+		},
+	},
+	"fumua": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			flat, ok := args[0].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			nested := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			for _, key := range flat.Keys() {
+				pair := flat.Pairs[key]
+				path, ok := pair.Key.(*object.String)
+				if !ok {
+					return newError("Mstari %d: Samahani, njia lazima iwe NENO, sio %s", line, pair.Key.Type())
+				}
+				parts := strings.Split(path.Value, ".")
+
+				current := nested
+				for i, part := range parts {
+					if part == "" {
+						return newError("Mstari %d: Samahani, njia '%s' si sahihi", line, path.Value)
+					}
+					name := &object.String{Value: part}
+					if i == len(parts)-1 {
+						if _, exists := current.Pairs[name.HashKey()]; exists {
+							return newError("Mstari %d: Samahani, njia '%s' inagongana na njia nyingine", line, path.Value)
+						}
+						value, err := deepCopy(line, pair.Value)
+						if err != nil {
+							return err
+						}
+						current.Set(name.HashKey(), object.DictPair{Key: name, Value: value})
+						break
+					}
+					existing, exists := current.Pairs[name.HashKey()]
+					if !exists {
+						child := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+						current.Set(name.HashKey(), object.DictPair{Key: name, Value: child})
+						current = child
+						continue
+					}
+					child, ok := existing.Value.(*object.Dict)
+					if !ok {
+						return newError("Mstari %d: Samahani, njia '%s' inagongana na njia nyingine", line, path.Value)
+					}
+					current = child
+				}
+			}
+			return nested
+		},
+	},
+	"desimaliGawanya": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			a, ok := numberToFloat(args[0])
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			b, ok := numberToFloat(args[1])
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[1].Type())
+			}
+			// like /, dividing by zero gives infinity rather than an error
+			return &object.Float{Value: a / b}
+		},
+	},
+	"pindua": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			matrix, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			rows := make([]*object.Array, len(matrix.Elements))
+			for i, elem := range matrix.Elements {
+				row, ok := elem.(*object.Array)
+				if !ok {
+					return newError("Mstari %d: Samahani, kila safu lazima iwe ORODHA, safu %d ni %s", line, i, elem.Type())
+				}
+				if i > 0 && len(row.Elements) != len(rows[0].Elements) {
+					return newError("Mstari %d: Samahani, safu %d ina vitu %d lakini safu 0 ina vitu %d", line, i, len(row.Elements), len(rows[0].Elements))
+				}
+				rows[i] = row
+			}
+
+			if len(rows) == 0 {
+				return &object.Array{Elements: []object.Object{}}
+			}
+			columns := make([]object.Object, len(rows[0].Elements))
+			for j := range columns {
+				column := make([]object.Object, len(rows))
+				for i, row := range rows {
+					column[i] = row.Elements[j]
+				}
+				columns[j] = &object.Array{Elements: column}
+			}
+			return &object.Array{Elements: columns}
+		},
+	},
+	"parsejsoni": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			dec := json.NewDecoder(strings.NewReader(str.Value))
+			dec.UseNumber()
+			value, err := decodeJSON(dec)
+			if err == nil {
+				// anything after the first value makes the text invalid
+				if _, extra := dec.Token(); extra != io.EOF {
+					err = errors.New("kuna maandishi zaidi baada ya thamani")
+				}
+			}
+			if err != nil {
+				return newError("Mstari %d: Samahani, JSON si sahihi: %s", line, err)
+			}
+			return value
+		},
+	},
+	"jsoni": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			var out strings.Builder
+			if err := encodeJSON(&out, args[0], map[object.Object]bool{}); err != nil {
+				return newError("Mstari %d: Samahani, %s", line, err)
+			}
+			return &object.String{Value: out.String()}
+		},
+	},
+	"marudio": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+
+			counts := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			for i, elem := range arr.Elements {
+				hashable, ok := elem.(object.Hashable)
+				if !ok {
+					return newError("Mstari %d: Samahani, kipengele %d ni %s, hakiwezi kuhesabiwa", line, i, elem.Type())
+				}
+				hashed := hashable.HashKey()
+				count := int64(0)
+				if pair, ok := counts.Pairs[hashed]; ok {
+					count = pair.Value.(*object.Integer).Value
+				}
+				counts.Set(hashed, object.DictPair{Key: elem, Value: newInteger(count + 1)})
+			}
+			return counts
+		},
+	},
+	"tengeneza": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			template, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe NENO, sio %s", line, args[0].Type())
+			}
+			values, ok := args[1].(*object.Dict)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe KAMUSI, sio %s", line, args[1].Type())
+			}
+			return &object.String{Value: fillTemplate(template.Value, values)}
+		},
+	},
+	"kikomo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			if len(arr.Elements) == 0 {
+				return newError("Mstari %d: Samahani, orodha tupu haina ndogo wala kubwa", line)
+			}
+			return numberBounds(line, arr.Elements)
+		},
+	},
+	"mudaKutokaNeno": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			d, err := time.ParseDuration(str.Value)
+			if err != nil {
+				return newError("Mstari %d: Samahani, muda '%s' hauko sahihi, tumia kama \"1h30m\" au \"500ms\"", line, str.Value)
+			}
+			return newInteger(d.Milliseconds())
+		},
+	},
+	"mudaKwaNeno": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			ms, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+			}
+			if ms.Value > math.MaxInt64/int64(time.Millisecond) || ms.Value < math.MinInt64/int64(time.Millisecond) {
+				return newError("Mstari %d: Samahani, muda %d ni mkubwa mno", line, ms.Value)
+			}
+			return &object.String{Value: (time.Duration(ms.Value) * time.Millisecond).String()}
+		},
+	},
+	"madirisha": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+			}
+			size, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe NAMBA, sio %s", line, args[1].Type())
+			}
+			if size.Value <= 0 {
+				return newError("Mstari %d: Samahani, ukubwa wa dirisha lazima uwe zaidi ya 0, sio %d", line, size.Value)
+			}
+
+			windows := &object.Array{Elements: []object.Object{}}
+			if size.Value > int64(len(arr.Elements)) {
+				return windows
+			}
+			n := int(size.Value)
+			for i := 0; i+n <= len(arr.Elements); i++ {
+				window := make([]object.Object, n)
+				copy(window, arr.Elements[i:i+n])
+				windows.Elements = append(windows.Elements, &object.Array{Elements: window})
+			}
+			return windows
+		},
+	},
+	"zoteMbili": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]) && isTruthy(args[1]))
+		},
+	},
+	"mojawapo": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			return nativeBoolToBooleanObject(isTruthy(args[0]) || isTruthy(args[1]))
+		},
+	},
+	"onyesha": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			if err, ok := args[0].(*object.Error); ok {
+				return &object.String{Value: err.Plain()}
+			}
+			return &object.String{Value: args[0].Inspect()}
+		},
+	},
+	"ukubwaWaKumbukumbu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			return newInteger(estimateSize(args[0], map[object.Object]bool{}))
+		},
+	},
+	"ukcd": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return foldIntegers(line, "ukcd", args, gcd)
+		},
+	},
+	"ukuu": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			return foldIntegers(line, "ukuu", args, lcm)
+		},
+	},
+	"desimaliKwaNeno": {
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			digits, ok := args[1].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe NAMBA, sio %s", line, args[1].Type())
+			}
+			if digits.Value < 0 {
+				return newError("Mstari %d: Samahani, idadi ya tarakimu haiwezi kuwa hasi: %d", line, digits.Value)
+			}
+			if digits.Value > maxDecimalDigits {
+				return newError("Mstari %d: Samahani, idadi ya tarakimu haiwezi kuzidi %d, sio %d", line, maxDecimalDigits, digits.Value)
+			}
+			switch x := args[0].(type) {
+			case *object.Integer:
+				return &object.String{Value: roundDecimalText(strconv.FormatInt(x.Value, 10), int(digits.Value))}
+			case *object.Float:
+				if math.IsNaN(x.Value) || math.IsInf(x.Value, 0) {
+					return &object.String{Value: x.Inspect()}
+				}
+				return &object.String{Value: roundDecimalText(strconv.FormatFloat(x.Value, 'f', -1, 64), int(digits.Value))}
+			default:
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe namba, sio %s", line, args[0].Type())
+			}
+		},
+	},
+}
+
+// numberToFloat returns the value of an integer or float object as a float64.
+func numberToFloat(obj object.Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value), true
+	case *object.Float:
+		return obj.Value, true
+	default:
+		return 0, false
+	}
+}
+
+// formatIntegerBase returns the digits of an integer in the given base
+// without any prefix. Negative numbers keep their sign, eg -5 in base 2 is "-101".
+func formatIntegerBase(line int, args []object.Object, base int) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	n, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	return &object.String{Value: strconv.FormatInt(n.Value, base)}
+}
+
+// Builtins that call back into Nuru functions go through applyFunction, which
+// itself looks up builtins, so they are registered here to avoid an
+// initialization cycle.
+func init() {
+	builtins["kubwaKwa"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			return selectByKey(line, args, ">")
+		},
+	}
+	builtins["ndogoKwa"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			return selectByKey(line, args, "<")
+		},
+	}
+	builtins["kundi"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+			}
+
+			groups := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			for _, elem := range arr.Elements {
+				key := applyFunction(args[1], []object.Object{elem}, line)
+				if isError(key) {
+					return key
+				}
+				hashKey, ok := key.(object.Hashable)
+				if !ok {
+					return newError("Mstari %d: Samahani, %s haitumiki kama key", line, key.Type())
+				}
+				hashed := hashKey.HashKey()
+				pair, ok := groups.Pairs[hashed]
+				if !ok {
+					pair = object.DictPair{Key: key, Value: &object.Array{}}
+				}
+				group := pair.Value.(*object.Array)
+				group.Elements = append(group.Elements, elem)
+				groups.Set(hashed, pair)
+			}
+			return groups
+		},
+	}
+	builtins["pimaMuda"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+			}
+			start := time.Now()
+			result := applyFunction(args[0], []object.Object{}, line)
+			elapsed := time.Since(start)
+			if isError(result) {
+				return result
+			}
+			millis := &object.Float{Value: float64(elapsed.Nanoseconds()) / 1e6}
+			return &object.Array{Elements: []object.Object{result, millis}}
+		},
+	}
+	builtins["tumiaFn"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[1].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe ORODHA, sio %s", line, args[1].Type())
+			}
+			callArgs := make([]object.Object, len(arr.Elements))
+			copy(callArgs, arr.Elements)
+			return applyFunction(args[0], callArgs, line)
+		},
+	}
+	builtins["changanua"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 && len(args) != 3 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[1].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya pili lazima iwe ORODHA, sio %s", line, args[1].Type())
+			}
+
+			// without awali the first element starts the running value
+			elements := arr.Elements
+			results := make([]object.Object, 0, len(elements))
+			var acc object.Object
+			if len(args) == 3 {
+				acc = args[2]
+			} else if len(elements) > 0 {
+				acc = elements[0]
+				elements = elements[1:]
+				results = append(results, acc)
+			}
+
+			for _, elem := range elements {
+				acc = applyFunction(args[0], []object.Object{acc, elem}, line)
+				if isError(acc) {
+					return acc
+				}
+				results = append(results, acc)
+			}
+			return &object.Array{Elements: results}
+		},
+	}
+	builtins["tafutaKwanza"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+			}
+			for _, elem := range arr.Elements {
+				found := applyFunction(args[1], []object.Object{elem}, line)
+				if isError(found) {
+					return found
+				}
+				if isTruthy(found) {
+					return elem
+				}
+			}
+			return NULL
+		},
+	}
+	builtins["rudiaFn"] = &object.Builtin{
+		Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 2 {
+				return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+			}
+			n, ok := args[0].(*object.Integer)
+			if !ok {
+				return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe NAMBA, sio %s", line, args[0].Type())
+			}
+			if n.Value < 0 {
+				return newError("Mstari %d: Samahani, idadi ya marudio haiwezi kuwa hasi: %d", line, n.Value)
+			}
+
+			// a function without parameters is called without the index
+			passIndex := true
+			if fn, ok := args[1].(*object.Function); ok && len(fn.Parameters) == 0 {
+				passIndex = false
+			}
+
+			// n comes from the program, so only a little is reserved up front
+			capacity := n.Value
+			if capacity > 1024 {
+				capacity = 1024
+			}
+			results := make([]object.Object, 0, capacity)
+			for i := int64(0); i < n.Value; i++ {
+				callArgs := []object.Object{}
+				if passIndex {
+					callArgs = append(callArgs, newInteger(i))
+				}
+				result := applyFunction(args[1], callArgs, line)
+				if isError(result) {
+					return result
+				}
+				results = append(results, result)
+			}
+			return &object.Array{Elements: results}
+		},
+	}
+}
+
+// selectByKey returns the element whose fn(element) wins the comparison
+// against every other key. Ties keep the first element.
+func selectByKey(line int, args []object.Object, operator string) object.Object {
+	if len(args) != 2 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe ORODHA, sio %s", line, args[0].Type())
+	}
+	if len(arr.Elements) == 0 {
+		return newError("Mstari %d: Samahani, orodha haina kitu", line)
+	}
+
+	var best, bestKey object.Object
+	for _, elem := range arr.Elements {
+		key := applyFunction(args[1], []object.Object{elem}, line)
+		if isError(key) {
+			return key
+		}
+		if best == nil {
+			best, bestKey = elem, key
+			continue
+		}
+		wins := evalInfixExpression(operator, key, bestKey, line)
+		if isError(wins) {
+			return wins
+		}
+		if wins == TRUE {
+			best, bestKey = elem, key
+		}
+	}
+	return best
+}
+
+// httpClient is used by pata and tuma. Tests swap it for one pointing
+// at an httptest server.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// doRequest sends req and returns the response as a dict with the keys
+// msimbo (status code), mwili (body) and vichwa (headers)
+func doRequest(line int, req *http.Request) object.Object {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return newError("Mstari %d: Samahani, ombi limeshindikana: %s", line, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return newError("Mstari %d: Samahani, imeshindikana kusoma jibu: %s", line, err)
+	}
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+	for _, name := range names {
+		key := &object.String{Value: name}
+		value := &object.String{Value: strings.Join(resp.Header[name], ", ")}
+		headers.Set(key.HashKey(), object.DictPair{Key: key, Value: value})
+	}
+
+	result := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+	fields := []object.DictPair{
+		{Key: &object.String{Value: "msimbo"}, Value: &object.Integer{Value: int64(resp.StatusCode)}},
+		{Key: &object.String{Value: "mwili"}, Value: &object.String{Value: string(body)}},
+		{Key: &object.String{Value: "vichwa"}, Value: headers},
+	}
+	for _, pair := range fields {
+		result.Set(pair.Key.(*object.String).HashKey(), pair)
+	}
+	return result
+}
+
+// convertString applies convert to a single string argument, turning a
+// failed conversion into a Nuru error
+func convertString(line int, args []object.Object, convert func(string) (string, error)) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	result, err := convert(str.Value)
+	if err != nil {
+		return newError("Mstari %d: Samahani, neno hili haliwezi kufunguliwa: %s", line, err)
+	}
+	return &object.String{Value: result}
+}
+
+// groupThousands puts separator between every three digits of the whole
+// number part of a formatted number, eg -1234567.5 becomes -1,234,567.5
+func groupThousands(digits, separator string) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(digits, ".")
+
+	var out strings.Builder
+	out.WriteString(sign)
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			out.WriteString(separator)
+		}
+		out.WriteRune(d)
+	}
+	if hasFraction {
+		out.WriteString("." + fraction)
+	}
+	return out.String()
+}
+
+// findSubstring runs find on two string arguments and turns the byte
+// index it returns into a character index, so it lines up with slicing
+func findSubstring(line int, args []object.Object, find func(s, substr string) int) object.Object {
+	if len(args) != 2 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 2, wewe umeweka %d", line, len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("Mstari %d: Samahani, hoja ya kwanza lazima iwe NENO, sio %s", line, args[0].Type())
+	}
+	substr, ok := args[1].(*object.String)
+	if !ok {
+		return newError("Mstari %d: Samahani, hoja ya pili lazima iwe NENO, sio %s", line, args[1].Type())
+	}
+
+	idx := find(str.Value, substr.Value)
+	if idx < 0 {
+		return &object.Integer{Value: -1}
+	}
+	return &object.Integer{Value: int64(utf8.RuneCountInString(str.Value[:idx]))}
+}
+
+// bitOperation applies fn to the 64 bit two's complement form of an integer,
+// so negative numbers have their sign bit set, eg hesabuBiti(-1) is 64.
+func bitOperation(line int, args []object.Object, fn func(uint64) int64) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	n, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	return &object.Integer{Value: fn(uint64(n.Value))}
+}
+
+// dictColumn collects one part of every pair in a dict, in insertion order.
+func dictColumn(line int, args []object.Object, part func(object.DictPair) object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	dict, ok := args[0].(*object.Dict)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+
+	elements := make([]object.Object, 0, len(dict.Pairs))
+	for _, key := range dict.Keys() {
+		elements = append(elements, part(dict.Pairs[key]))
+	}
+	return &object.Array{Elements: elements}
+}
+
+// convertUtf8 checks for a single string argument before handing its raw
+// bytes to fn, which may hold invalid UTF-8 when read from a file.
+func convertUtf8(line int, args []object.Object, fn func(string) object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	str, ok := args[0].(*object.String)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	return fn(str.Value)
+}
+
+// pathStep looks up one key of a path, a key in a dict or an index in an
+// array. It reports false when there is nothing there to step into.
+func pathStep(container, key object.Object) (object.Object, bool) {
+	switch container := container.(type) {
+	case *object.Dict:
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return nil, false
+		}
+		pair, ok := container.Pairs[hashKey.HashKey()]
+		return pair.Value, ok
+	case *object.Array:
+		idx, ok := key.(*object.Integer)
+		if !ok || idx.Value < 0 || idx.Value >= int64(len(container.Elements)) {
+			return nil, false
+		}
+		return container.Elements[idx.Value], true
+	default:
+		return nil, false
+	}
+}
+
+// roundNumber turns a decimal into a NAMBA using fn. Integers are already
+// whole so they come back unchanged.
+func roundNumber(line int, arg object.Object, fn func(float64) float64) object.Object {
+	switch arg := arg.(type) {
+	case *object.Integer:
+		return arg
+	case *object.Float:
+		if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+			return newError("Mstari %d: Samahani, %s haiwezi kuzungushwa", line, arg.Inspect())
+		}
+		rounded := fn(arg.Value)
+		if rounded < math.MinInt64 || rounded >= math.MaxInt64 {
+			return newError("Mstari %d: Samahani, %s ni kubwa mno kuwa NAMBA", line, arg.Inspect())
+		}
+		return &object.Integer{Value: int64(rounded)}
+	default:
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, arg.Type())
+	}
+}
+
+// checkNumber reports fn of a single number argument. Integers are checked
+// as decimals, so they are never NaN or infinite and are always whole.
+func checkNumber(line int, args []object.Object, fn func(float64) bool) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	x, ok := numberToFloat(args[0])
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	return nativeBoolToBooleanObject(fn(x))
+}
+
+// writeLog writes one line like
+//
+//	2023-01-02T15:04:05+03:00 [ONYO] diski inajaa nafasi=10 kitengo="GB"
+//
+// where the optional dict of fields follows the message in insertion
+// order. String values are quoted so fields with spaces stay readable.
+func writeLog(line int, level string, args []object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1 au 2, wewe umeweka %d", line, len(args))
+	}
+
+	var out strings.Builder
+	out.WriteString(time.Now().Format(time.RFC3339))
+	out.WriteString(" [" + level + "] ")
+	out.WriteString(args[0].Inspect())
+
+	if len(args) == 2 {
+		fields, ok := args[1].(*object.Dict)
+		if !ok {
+			return newError("Mstari %d: Samahani, sehemu lazima ziwe KAMUSI, sio %s", line, args[1].Type())
+		}
+		for _, key := range fields.Keys() {
+			pair := fields.Pairs[key]
+			value := pair.Value.Inspect()
+			if _, ok := pair.Value.(*object.String); ok {
+				value = strconv.Quote(value)
+			}
+			out.WriteString(" " + pair.Key.Inspect() + "=" + value)
+		}
+	}
+
+	out.WriteString("\n")
+	io.WriteString(logOutput, out.String())
+	return nil
+}
+
+// deepMerge returns a new dict with the pairs of b merged into a. When
+// both sides hold a dict under the same key those are merged too, any
+// other clash is won by b. Nothing in the result is shared with a or b.
+func deepMerge(line int, a, b *object.Dict) (*object.Dict, *object.Error) {
+	copied, err := deepCopy(line, a)
+	if err != nil {
+		return nil, err
+	}
+	merged := copied.(*object.Dict)
+	for _, key := range b.Keys() {
+		pair := b.Pairs[key]
+		if existing, ok := merged.Pairs[key]; ok {
+			left, leftIsDict := existing.Value.(*object.Dict)
+			right, rightIsDict := pair.Value.(*object.Dict)
+			if leftIsDict && rightIsDict {
+				// left is a fresh copy with no cycles, so this ends once
+				// it runs out even if right holds itself
+				value, err := deepMerge(line, left, right)
+				if err != nil {
+					return nil, err
+				}
+				merged.Set(key, object.DictPair{Key: existing.Key, Value: value})
+				continue
+			}
+		}
+		value, err := deepCopy(line, pair.Value)
+		if err != nil {
+			return nil, err
+		}
+		merged.Set(key, object.DictPair{Key: pair.Key, Value: value})
+	}
+	return merged, nil
+}
+
+// deepCopy copies dicts, arrays, sets and builders all the way down.
+// Numbers, strings and the rest can not be changed, so they are returned
+// as they are. A dict or array that holds itself can not be copied, and
+// is an error.
+func deepCopy(line int, obj object.Object) (object.Object, *object.Error) {
+	return copyValue(line, obj, map[object.Object]bool{})
+}
+
+// copyValue does the work of deepCopy. path holds the containers being
+// copied around obj, so one found again inside itself is a cycle.
+func copyValue(line int, obj object.Object, path map[object.Object]bool) (object.Object, *object.Error) {
+	switch obj.(type) {
+	case *object.Dict, *object.Array, *object.Builder:
+		if path[obj] {
+			return nil, newError("Mstari %d: Samahani, muundo unajirudia, hauwezi kunakiliwa", line)
+		}
+		path[obj] = true
+		defer delete(path, obj)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Dict:
+		dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair, len(obj.Pairs))}
+		for _, key := range obj.Keys() {
+			pair := obj.Pairs[key]
+			value, err := copyValue(line, pair.Value, path)
+			if err != nil {
+				return nil, err
+			}
+			dict.Set(key, object.DictPair{Key: pair.Key, Value: value})
+		}
+		return dict, nil
+	case *object.Array:
+		elements := make([]object.Object, len(obj.Elements))
+		for i, el := range obj.Elements {
+			value, err := copyValue(line, el, path)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return &object.Array{Elements: elements}, nil
+	case *object.Set:
+		// members are hashable, and hashable values can not be changed
+		set := &object.Set{}
+		for _, el := range obj.Elements() {
+			set.Add(el.(object.Hashable))
+		}
+		return set, nil
+	case *object.Builder:
+		builder := &object.Builder{Array: obj.Array}
+		switch built := obj.Result().(type) {
+		case *object.Array:
+			for _, el := range built.Elements {
+				value, err := copyValue(line, el, path)
+				if err != nil {
+					return nil, err
+				}
+				builder.Add(value)
+			}
+		default:
+			builder.Add(built)
+		}
+		return builder, nil
+	default:
+		return obj, nil
+	}
+}
+
+// extremeLength returns the length in characters of the string in an
+// array for which wins(length, best) holds against all the others
+func extremeLength(line int, args []object.Object, wins func(a, b int) bool) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	if len(arr.Elements) == 0 {
+		return newError("Mstari %d: Samahani, orodha haina kitu", line)
+	}
+
+	best := 0
+	for i, elem := range arr.Elements {
+		str, ok := elem.(*object.String)
+		if !ok {
+			return newError("Mstari %d: Samahani, orodha lazima iwe na NENO tu, sio %s", line, elem.Type())
+		}
+		length := utf8.RuneCountInString(str.Value)
+		if i == 0 || wins(length, best) {
+			best = length
+		}
+	}
+	return &object.Integer{Value: int64(best)}
+}
+
+// keyByValue returns the key of the dict whose value wins the comparison
+// against every other value. Ties keep the key that was added first.
+func keyByValue(line int, args []object.Object, operator string) object.Object {
+	if len(args) != 1 {
+		return newError("Mstari %d: Samahani, tunahitaji Hoja 1, wewe umeweka %d", line, len(args))
+	}
+	dict, ok := args[0].(*object.Dict)
+	if !ok {
+		return newError("Mstari %d: Samahani, hii function haitumiki na %s", line, args[0].Type())
+	}
+	if len(dict.Pairs) == 0 {
+		return newError("Mstari %d: Samahani, kamusi haina kitu", line)
+	}
+
+	var best object.DictPair
+	for i, key := range dict.Keys() {
+		pair := dict.Pairs[key]
+		if !isNumber(pair.Value) && pair.Value.Type() != object.STRING_OBJ {
+			return newError("Mstari %d: Samahani, thamani lazima ziwe NAMBA, DESIMALI au NENO, sio %s", line, pair.Value.Type())
+		}
+		if i == 0 {
+			best = pair
+			continue
+		}
+		// strings have no < or >, so they are compared here
+		left, leftIsString := pair.Value.(*object.String)
+		right, rightIsString := best.Value.(*object.String)
+		if leftIsString && rightIsString {
+			if (operator == ">" && left.Value > right.Value) || (operator == "<" && left.Value < right.Value) {
+				best = pair
+			}
+			continue
+		}
+		wins := evalInfixExpression(operator, pair.Value, best.Value, line)
+		if isError(wins) {
+			return wins
+		}
+		if wins == TRUE {
+			best = pair
+		}
+	}
+	return best.Key
+}
+
+// flattenDict adds the leaves of dict to flat under their dotted paths,
+// eg {"a": {"b": 1}} becomes {"a.b": 1}. Empty dicts are kept as leaves
+// so fumua can bring them back. Keys must be strings without dots, since
+// a dot inside a key could not be told apart from one between keys.
+// parents holds the dicts above this one, to catch a dict inside itself.
+func flattenDict(line int, dict *object.Dict, prefix string, flat *object.Dict, parents map[*object.Dict]bool) *object.Error {
+	if parents[dict] {
+		return newError("Mstari %d: Samahani, muundo unajirudia kwenye njia '%s'", line, prefix)
+	}
+	parents[dict] = true
+	defer delete(parents, dict)
+
+	for _, key := range dict.Keys() {
+		pair := dict.Pairs[key]
+		name, ok := pair.Key.(*object.String)
+		if !ok {
+			return newError("Mstari %d: Samahani, key lazima iwe NENO, sio %s", line, pair.Key.Type())
+		}
+		if name.Value == "" || strings.Contains(name.Value, ".") {
+			return newError("Mstari %d: Samahani, key '%s' haiwezi kuwa tupu wala kuwa na nukta", line, name.Value)
+		}
+
+		path := name.Value
+		if prefix != "" {
+			path = prefix + "." + name.Value
+		}
+		if child, ok := pair.Value.(*object.Dict); ok && len(child.Pairs) > 0 {
+			if err := flattenDict(line, child, path, flat, parents); err != nil {
+				return err
+			}
+			continue
+		}
+		flatKey := &object.String{Value: path}
+		value, err := deepCopy(line, pair.Value)
+		if err != nil {
+			return err
+		}
+		flat.Set(flatKey.HashKey(), object.DictPair{Key: flatKey, Value: value})
+	}
+	return nil
+}
+
+// decodeJSON reads the next JSON value from dec. It reads token by token
+// rather than into a Go map so objects keep the order of their keys, and
+// numbers without a fraction or exponent become integers.
+func decodeJSON(dec *json.Decoder) (object.Object, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '[':
+			elements := []object.Object{}
+			for dec.More() {
+				elem, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, elem)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return &object.Array{Elements: elements}, nil
+		case '{':
+			dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key := &object.String{Value: keyTok.(string)}
+				value, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				dict.Set(key.HashKey(), object.DictPair{Key: key, Value: value})
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return dict, nil
+		}
+		return nil, fmt.Errorf("alama isiyotarajiwa %s", tok)
+	case json.Number:
+		if !strings.ContainsAny(tok.String(), ".eE") {
+			if i, err := tok.Int64(); err == nil {
+				return &object.Integer{Value: i}, nil
+			}
+		}
+		f, err := tok.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &object.Float{Value: f}, nil
+	case string:
+		return &object.String{Value: tok}, nil
+	case bool:
+		return nativeBoolToBooleanObject(tok), nil
+	default:
+		return NULL, nil
+	}
+}
+
+// encodeJSON writes obj to out as JSON. Whole floats keep a trailing .0
+// so that parsejsoni reads them back as floats and not integers. parents
+// holds the arrays and dicts being written around obj, since one that
+// holds itself would never end.
+func encodeJSON(out *strings.Builder, obj object.Object, parents map[object.Object]bool) error {
+	switch obj.(type) {
+	case *object.Array, *object.Dict:
+		if parents[obj] {
+			return errors.New("muundo unajirudia, hauwezi kuwa JSON")
+		}
+		parents[obj] = true
+		defer delete(parents, obj)
+	}
+
+	switch obj := obj.(type) {
+	case *object.Integer:
+		out.WriteString(strconv.FormatInt(obj.Value, 10))
+	case *object.Float:
+		if math.IsInf(obj.Value, 0) || math.IsNaN(obj.Value) {
+			return fmt.Errorf("JSON haiwezi kuwa na %s", obj.Inspect())
+		}
+		f := strconv.FormatFloat(obj.Value, 'f', -1, 64)
+		if !strings.Contains(f, ".") {
+			f += ".0"
+		}
+		out.WriteString(f)
+	case *object.String:
+		encoded, _ := json.Marshal(obj.Value)
+		out.Write(encoded)
+	case *object.Boolean:
+		out.WriteString(strconv.FormatBool(obj.Value))
+	case *object.Null:
+		out.WriteString("null")
+	case *object.Array:
+		out.WriteString("[")
+		for i, elem := range obj.Elements {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			if err := encodeJSON(out, elem, parents); err != nil {
+				return err
+			}
+		}
+		out.WriteString("]")
+	case *object.Dict:
+		out.WriteString("{")
+		for i, key := range obj.Keys() {
+			pair := obj.Pairs[key]
+			name, ok := pair.Key.(*object.String)
+			if !ok {
+				return fmt.Errorf("key za JSON lazima ziwe NENO, sio %s", pair.Key.Type())
+			}
+			if i > 0 {
+				out.WriteString(",")
+			}
+			encoded, _ := json.Marshal(name.Value)
+			out.Write(encoded)
+			out.WriteString(":")
+			if err := encodeJSON(out, pair.Value, parents); err != nil {
+				return err
+			}
+		}
+		out.WriteString("}")
+	default:
+		return fmt.Errorf("%s haiwezi kuwa JSON", obj.Type())
+	}
+	return nil
+}
+
+// fillTemplate replaces each {{jina}} in template with the value of the
+// key "jina" in values. Spaces around the name are ignored. Placeholders
+// whose key is missing are left as they are, so a partly filled template
+// can be filled again later.
+func fillTemplate(template string, values *object.Dict) string {
+	var out strings.Builder
+	for {
+		start := strings.Index(template, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(template[start+2:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+
+		out.WriteString(template[:start])
+		name := &object.String{Value: strings.TrimSpace(template[start+2 : end])}
+		if pair, ok := values.Pairs[name.HashKey()]; ok {
+			out.WriteString(pair.Value.Inspect())
+		} else {
+			out.WriteString(template[start : end+2])
+		}
+		template = template[end+2:]
+	}
+	out.WriteString(template)
+	return out.String()
+}
+
+// numberBounds finds the smallest and largest of nums in one pass and
+// returns them as [ndogo, kubwa]. Integers are compared exactly; once a
+// float turns up both bounds are given as floats.
+func numberBounds(line int, nums []object.Object) object.Object {
+	var lowInt, highInt int64
+	var lowFloat, highFloat float64
+	hasInt, hasFloat := false, false
+
+	for i, num := range nums {
+		switch num := num.(type) {
+		case *object.Integer:
+			if !hasInt || num.Value < lowInt {
+				lowInt = num.Value
+			}
+			if !hasInt || num.Value > highInt {
+				highInt = num.Value
+			}
+			hasInt = true
+		case *object.Float:
+			if !hasFloat || num.Value < lowFloat {
+				lowFloat = num.Value
+			}
+			if !hasFloat || num.Value > highFloat {
+				highFloat = num.Value
+			}
+			hasFloat = true
+		default:
+			return newError("Mstari %d: Samahani, kipengele %d ni %s, namba tu zinahitajika", line, i, num.Type())
+		}
+	}
+
+	if !hasFloat {
+		return &object.Array{Elements: []object.Object{newInteger(lowInt), newInteger(highInt)}}
+	}
+	if hasInt {
+		lowFloat = math.Min(lowFloat, float64(lowInt))
+		highFloat = math.Max(highFloat, float64(highInt))
+	}
+	return &object.Array{Elements: []object.Object{&object.Float{Value: lowFloat}, &object.Float{Value: highFloat}}}
+}
+
+// Sizes used by estimateSize, roughly what each kind of value costs in Go
+const (
+	sizeWord      = 8  // a number, or a pointer to an element
+	sizeString    = 16 // a string header, before its bytes
+	sizeContainer = 24 // the header of an array, dict or set
+	sizeOther     = 32 // functions, builtins and anything else
+)
+
+// estimateSize gives an approximate number of bytes held by obj: 8 for a
+// number, 1 for kweli, sikweli and tupu, 16 plus the bytes of a string,
+// and for arrays, dicts and sets 24 plus a pointer per element or key and
+// value, plus the size of everything they hold. A container reached twice,
+// eg an array inside itself, is only counted the first time.
+func estimateSize(obj object.Object, seen map[object.Object]bool) int64 {
+	switch obj := obj.(type) {
+	case *object.Integer, *object.Float:
+		return sizeWord
+	case *object.Boolean, *object.Null:
+		return 1
+	case *object.String:
+		return sizeString + int64(len(obj.Value))
+	case *object.Array:
+		if seen[obj] {
+			return sizeWord
+		}
+		seen[obj] = true
+		size := int64(sizeContainer)
+		for _, elem := range obj.Elements {
+			size += sizeWord + estimateSize(elem, seen)
+		}
+		return size
+	case *object.Dict:
+		if seen[obj] {
+			return sizeWord
+		}
+		seen[obj] = true
+		size := int64(sizeContainer)
+		for _, pair := range obj.Pairs {
+			size += 2*sizeWord + estimateSize(pair.Key, seen) + estimateSize(pair.Value, seen)
+		}
+		return size
+	case *object.Set:
+		if seen[obj] {
+			return sizeWord
+		}
+		seen[obj] = true
+		size := int64(sizeContainer)
+		for _, elem := range obj.Elements() {
+			size += sizeWord + estimateSize(elem, seen)
+		}
+		return size
+	default:
+		return sizeOther
+	}
+}
+
+// foldIntegers combines two or more integers with combine, left to right,
+// eg ukcd(a, b, c) is ukcd(ukcd(a, b), c). Signs are dropped first, and a
+// result too large for NAMBA is an error.
+func foldIntegers(line int, name string, args []object.Object, combine func(a, b uint64) (uint64, bool)) object.Object {
+	if len(args) < 2 {
+		return newError("Mstari %d: Samahani, %s inahitaji angalau Hoja 2, wewe umeweka %d", line, name, len(args))
+	}
+	var result uint64
+	for i, arg := range args {
+		n, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("Mstari %d: Samahani, %s inatumika na NAMBA tu, sio %s", line, name, arg.Type())
+		}
+		abs := uint64(n.Value)
+		if n.Value < 0 {
+			abs = -abs
+		}
+		if i == 0 {
+			result = abs
+			continue
+		}
+		if result, ok = combine(result, abs); !ok {
+			return newError("Mstari %d: Samahani, jibu la %s ni kubwa mno", line, name)
+		}
+	}
+	if result > math.MaxInt64 {
+		return newError("Mstari %d: Samahani, jibu la %s ni kubwa mno", line, name)
+	}
+	return newInteger(int64(result))
+}
+
+func gcd(a, b uint64) (uint64, bool) {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a, true
+}
+
+// lcm is 0 when either number is 0, and not ok when it overflows
+func lcm(a, b uint64) (uint64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	g, _ := gcd(a, b)
+	hi, lo := bits.Mul64(a/g, b)
+	return lo, hi == 0
+}
+
+// maxDecimalDigits is the most decimal places desimaliKwaNeno writes
+const maxDecimalDigits = 100
+
+// roundDecimalText rounds the decimal number in text to digits places,
+// half away from zero like zungushaKaribu, and pads it with zeros to
+// exactly that many places. It works on the digits as written, so 2.675
+// rounds to 2.68 even though the float closest to it is a little less.
+func roundDecimalText(text string, digits int) string {
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+	whole, frac, _ := strings.Cut(text, ".")
+
+	roundUp := len(frac) > digits && frac[digits] >= '5'
+	if len(frac) > digits {
+		frac = frac[:digits]
+	} else {
+		frac += strings.Repeat("0", digits-len(frac))
+	}
+
+	number := []byte(whole + frac)
+	if roundUp {
+		i := len(number) - 1
+		for ; i >= 0 && number[i] == '9'; i-- {
+			number[i] = '0'
+		}
+		if i < 0 {
+			number = append([]byte{'1'}, number...)
+		} else {
+			number[i]++
+		}
+	}
+
+	split := len(number) - digits
+	result := string(number[:split])
+	if digits > 0 {
+		result += "." + string(number[split:])
+	}
+	if negative && strings.Trim(string(number), "0") != "" {
+		result = "-" + result
+	}
+	return result
+}
+
+// maxRangeLength is the most elements mfululizo will make
+const maxRangeLength = 10000000
+
+// integerRange is mfululizo for integers. The count is worked out in
+// unsigned arithmetic, since stop - start can be too large for an int64.
+func integerRange(line int, start, stop, step int64) object.Object {
+	// a step that moves away from stop leaves span at 0, so no elements
+	span, stride := uint64(0), uint64(1)
+	switch {
+	case step > 0 && stop > start:
+		span, stride = uint64(stop)-uint64(start), uint64(step)
+	case step < 0 && stop < start:
+		span, stride = uint64(start)-uint64(stop), -uint64(step)
+	}
+	count := span / stride
+	if span%stride != 0 {
+		count++
+	}
+	if count > maxRangeLength {
+		return newError("Mstari %d: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu %d", line, maxRangeLength)
+	}
+
+	elements := make([]object.Object, count)
+	for i := range elements {
+		elements[i] = newInteger(int64(uint64(start) + uint64(i)*uint64(step)))
+	}
+	return &object.Array{Elements: elements}
 }
@@ -7,26 +7,93 @@ import (
 
 	"github.com/AvicennaJr/Nuru/ast"
 	"github.com/AvicennaJr/Nuru/object"
+	"github.com/AvicennaJr/Nuru/token"
 )
 
 var (
-	NULL     = &object.Null{}
-	TRUE     = &object.Boolean{Value: true}
-	FALSE    = &object.Boolean{Value: false}
-	BREAK    = &object.Break{}
-	CONTINUE = &object.Continue{}
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
 )
 
+// the range of integers that are shared instead of allocated every time
+const (
+	smallIntegerMin = -128
+	smallIntegerMax = 255
+)
+
+// smallIntegers holds one Integer for each value in the small range, the
+// same way TRUE and FALSE are shared. Nothing changes an Integer once it
+// is made, which is what makes sharing them safe.
+var smallIntegers = func() []*object.Integer {
+	ints := make([]*object.Integer, smallIntegerMax-smallIntegerMin+1)
+	for i := range ints {
+		ints[i] = &object.Integer{Value: int64(i + smallIntegerMin)}
+	}
+	return ints
+}()
+
+// newInteger returns the shared Integer for small values and a new one
+// for everything else
+func newInteger(value int64) *object.Integer {
+	if value >= smallIntegerMin && value <= smallIntegerMax {
+		return smallIntegers[value-smallIntegerMin]
+	}
+	return &object.Integer{Value: value}
+}
+
+// compoundOperators gives the infix operator that each compound assignment
+// applies, eg x **= 2 is x = x ** 2
+var compoundOperators = map[token.TokenType]string{
+	token.PLUS_ASSIGN:      "+",
+	token.MINUS_ASSIGN:     "-",
+	token.ASTERISK_ASSIGN:  "*",
+	token.SLASH_ASSIGN:     "/",
+	token.MODULUS_ASSIGN:   "%",
+	token.POW_ASSIGN:       "**",
+	token.FLOOR_DIV_ASSIGN: "~/",
+}
+
+// NewEnvironment returns the top level environment for running a
+// program, with the built in constants already bound.
+func NewEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.Set("TUPU", NULL)
+	env.Set("NAMBA_KUBWA", &object.Integer{Value: math.MaxInt64})
+	env.Set("NAMBA_NDOGO", &object.Integer{Value: math.MinInt64})
+	return env
+}
+
+// NewSandboxEnv is like NewEnvironment, but only the builtins named in
+// allowed can be used. Any other builtin is unknown to the program, so a
+// host can run untrusted code without giving it files or the network.
+func NewSandboxEnv(allowed []string) *object.Environment {
+	env := NewEnvironment()
+	available := make(map[string]*object.Builtin, len(allowed))
+	for _, name := range allowed {
+		if builtin, ok := builtins[name]; ok {
+			available[name] = builtin
+		}
+	}
+	env.SetBuiltins(available)
+	return env
+}
+
+// Eval runs node in env and returns its value. For a whole program that is
+// the value of the last statement, or the value given to a top level
+// rudisha, which ends the program there. Either way the result is the
+// plain value, never an *object.ReturnValue, so a host can read it
+// straight away. Errors come back as an *object.Error result.
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
-		return evalProgram(node, env)
+		return evalProgram(node, env, nil)
 
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return newInteger(node.Value)
 
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
@@ -73,6 +140,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 
+		if fn, ok := val.(*object.Function); ok && fn.Name == "" {
+			fn.Name = node.Name.Value
+		}
 		env.Set(node.Name.Value, val)
 
 	case *ast.Identifier:
@@ -92,6 +162,18 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
+		if len(node.Named) > 0 {
+			var err *object.Error
+			args, err = bindNamedArguments(function, args, node.Named, env, node.Token.Line)
+			if err != nil {
+				return err
+			}
+		}
+		switch function.(type) {
+		case *object.Function, *object.Builtin:
+		default:
+			return newError("Mstari %d: Hii sio function: %s ni %s", node.Token.Line, calleeName(node.Function), function.Type())
+		}
 		return applyFunction(function, args, node.Token.Line)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
@@ -107,15 +189,40 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
 		index := Eval(node.Index, env)
 		if isError(index) {
 			return index
 		}
 		return evalIndexExpression(left, index, node.Token.Line)
+	case *ast.SliceExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		if node.Optional && left == NULL {
+			return NULL
+		}
+		return evalSliceExpression(node, left, env)
 	case *ast.DictLiteral:
 		return evalDictLiteral(node, env)
+	case *ast.PropertyExpression:
+		obj := Eval(node.Object, env)
+		if isError(obj) {
+			return obj
+		}
+		if node.Optional && obj == NULL {
+			return NULL
+		}
+		return evalPropertyExpression(obj, node.Property.Value, node.Token.Line)
 	case *ast.WhileExpression:
 		return evalWhileExpression(node, env)
+	case *ast.BlockExpression:
+		return evalBlockExpression(node, env)
+	case *ast.YieldStatement:
+		return evalYieldStatement(node, env)
 	case *ast.Break:
 		return evalBreak(node)
 	case *ast.Continue:
@@ -128,25 +235,29 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	// 	return evalForExpression(node, env)
 	case *ast.ForIn:
 		return evalForInExpression(node, env, node.Token.Line)
+	case *ast.Comprehension:
+		return evalComprehension(node, env)
 	case *ast.AssignmentExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
 		}
 
+		// x ||= y and x &&= y short circuit, so y is only evaluated
+		// (and x only assigned) when x is falsy or truthy respectively
+		op := node.Token.Literal
+		logical := op == "||=" || op == "&&="
+		if logical && isTruthy(left) == (op == "||=") {
+			return nil
+		}
+
 		value := Eval(node.Value, env)
 		if isError(value) {
 			return value
 		}
 
-		// This is an easy way to assign operators like +=, -= etc
-		// I'm surprised it work at the first try lol
-		// basically separate the += to + and =, take the + only and
-		// then perform the operation as normal
-		op := node.Token.Literal
-		if len(op) >= 2 {
-			op = op[:len(op)-1]
-			value = evalInfixExpression(op, left, value, node.Token.Line)
+		if infix, ok := compoundOperators[node.Token.Type]; ok {
+			value = evalInfixExpression(infix, left, value, node.Token.Line)
 			if isError(value) {
 				return value
 			}
@@ -155,37 +266,16 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if ident, ok := node.Left.(*ast.Identifier); ok {
 			env.Set(ident.Value, value)
 		} else if ie, ok := node.Left.(*ast.IndexExpression); ok {
-			obj := Eval(ie.Left, env)
+			if err := assignIndex(ie, value, env); err != nil {
+				return err
+			}
+		} else if se, ok := node.Left.(*ast.SliceExpression); ok {
+			obj := Eval(se.Left, env)
 			if isError(obj) {
 				return obj
 			}
-
-			if array, ok := obj.(*object.Array); ok {
-				index := Eval(ie.Index, env)
-				if isError(index) {
-					return index
-				}
-				if idx, ok := index.(*object.Integer); ok {
-					if int(idx.Value) > len(array.Elements) {
-						return newError("Index imezidi idadi ya elements")
-					}
-					array.Elements[idx.Value] = value
-				} else {
-					return newError("Hauwezi kufanya opereshen hii na %#v", index)
-				}
-			} else if hash, ok := obj.(*object.Dict); ok {
-				key := Eval(ie.Index, env)
-				if isError(key) {
-					return key
-				}
-				if hashKey, ok := key.(object.Hashable); ok {
-					hashed := hashKey.HashKey()
-					hash.Pairs[hashed] = object.DictPair{Key: key, Value: value}
-				} else {
-					return newError("Hauwezi kufanya opereshen hii na %T", key)
-				}
-			} else {
-				return newError("%T haifanyi operation hii", obj)
+			if err := assignSlice(se, obj, value, env); err != nil {
+				return err
 			}
 		} else {
 			return newError("Tumia neno kama variable, sio %T", left)
@@ -196,7 +286,15 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	return nil
 }
 
-func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+// EvalInteractive runs a program the way the REPL does. The value of each
+// top level expression statement is passed to show as soon as it is
+// evaluated, unless it is tupu. Errors and rudisha still end the program
+// and are returned rather than shown.
+func EvalInteractive(program *ast.Program, env *object.Environment, show func(object.Object)) object.Object {
+	return evalProgram(program, env, show)
+}
+
+func evalProgram(program *ast.Program, env *object.Environment, show func(object.Object)) object.Object {
 	var result object.Object
 
 	for _, statment := range program.Statements {
@@ -208,6 +306,12 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 		case *object.Error:
 			return result
 		}
+		if err := loopControlError(result); err != nil {
+			return err
+		}
+		if _, ok := statment.(*ast.ExpressionStatement); ok && show != nil && result != nil && result != NULL {
+			show(result)
+		}
 	}
 
 	return result
@@ -255,6 +359,9 @@ func evalMinusPrefixOperatorExpression(right object.Object, line int) object.Obj
 	case *object.Float:
 		return &object.Float{Value: -obj.Value}
 
+	case *object.String:
+		return newError("Mstari %d: Operesheni Haielweki: -%s. Tumia nambari() kubadili neno kuwa namba kwanza", line, right.Type())
+
 	default:
 		return newError("Mstari %d: Operesheni Haielweki: -%s", line, right.Type())
 	}
@@ -268,8 +375,11 @@ func evalPlusPrefixOperatorExpression(right object.Object, line int) object.Obje
 	case *object.Float:
 		return &object.Float{Value: obj.Value}
 
+	case *object.String:
+		return newError("Mstari %d: Operesheni Haielweki: +%s. Tumia nambari() kubadili neno kuwa namba kwanza", line, right.Type())
+
 	default:
-		return newError("Mstari %d: Operesheni Haielweki: -%s", line, right.Type())
+		return newError("Mstari %d: Operesheni Haielweki: +%s", line, right.Type())
 	}
 }
 func evalInfixExpression(operator string, left, right object.Object, line int) object.Object {
@@ -277,20 +387,30 @@ func evalInfixExpression(operator string, left, right object.Object, line int) o
 		return newError("Mstari %d: Umekosea hapa", line)
 	}
 	switch {
+	case operator == "ktk":
+		return evalInExpression(left, right, line)
+
+	case operator == "si ktk":
+		found := evalInExpression(left, right, line)
+		if isError(found) {
+			return found
+		}
+		return nativeBoolToBooleanObject(!isTruthy(found))
+
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right, line)
 
 	case operator == "+" && left.Type() == object.DICT_OBJ && right.Type() == object.DICT_OBJ:
-		leftVal := left.(*object.Dict).Pairs
-		rightVal := right.(*object.Dict).Pairs
-		pairs := make(map[object.HashKey]object.DictPair)
-		for k, v := range leftVal {
-			pairs[k] = v
+		leftVal := left.(*object.Dict)
+		rightVal := right.(*object.Dict)
+		dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+		for _, k := range leftVal.Keys() {
+			dict.Set(k, leftVal.Pairs[k])
 		}
-		for k, v := range rightVal {
-			pairs[k] = v
+		for _, k := range rightVal.Keys() {
+			dict.Set(k, rightVal.Pairs[k])
 		}
-		return &object.Dict{Pairs: pairs}
+		return dict
 
 	case operator == "+" && left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
 		leftVal := left.(*object.Array).Elements
@@ -300,32 +420,19 @@ func evalInfixExpression(operator string, left, right object.Object, line int) o
 		return &object.Array{Elements: elements}
 
 	case operator == "*" && left.Type() == object.ARRAY_OBJ && right.Type() == object.INTEGER_OBJ:
-		leftVal := left.(*object.Array).Elements
-		rightVal := int(right.(*object.Integer).Value)
-		elements := leftVal
-		for i := rightVal; i > 1; i-- {
-			elements = append(elements, leftVal...)
-		}
-		return &object.Array{Elements: elements}
+		return repeatArray(left.(*object.Array), right.(*object.Integer).Value, line)
 
 	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.ARRAY_OBJ:
-		leftVal := int(left.(*object.Integer).Value)
-		rightVal := right.(*object.Array).Elements
-		elements := rightVal
-		for i := leftVal; i > 1; i-- {
-			elements = append(elements, rightVal...)
-		}
-		return &object.Array{Elements: elements}
+		return repeatArray(right.(*object.Array), left.(*object.Integer).Value, line)
 
 	case operator == "*" && left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
-		leftVal := left.(*object.String).Value
-		rightVal := right.(*object.Integer).Value
-		return &object.String{Value: strings.Repeat(leftVal, int(rightVal))}
+		return repeatString(left.(*object.String).Value, right.(*object.Integer).Value, line)
 
 	case operator == "*" && left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
-		leftVal := left.(*object.Integer).Value
-		rightVal := right.(*object.String).Value
-		return &object.String{Value: strings.Repeat(rightVal, int(leftVal))}
+		return repeatString(right.(*object.String).Value, left.(*object.Integer).Value, line)
+
+	case operator == "~/" && isNumber(left) && isNumber(right):
+		return evalFloorDivision(left, right, line)
 
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right, line)
@@ -339,9 +446,6 @@ func evalInfixExpression(operator string, left, right object.Object, line int) o
 	case left.Type() == object.FLOAT_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalFloatIntegerInfixExpression(operator, left, right, line)
 
-	case operator == "ktk":
-		return evalInExpression(left, right, line)
-
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 
@@ -366,22 +470,25 @@ func evalIntegerInfixExpression(operator string, left, right object.Object, line
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return newInteger(leftVal + rightVal)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return newInteger(leftVal - rightVal)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return newInteger(leftVal * rightVal)
 	case "**":
-		return &object.Integer{Value: int64(math.Pow(float64(leftVal), float64(rightVal)))}
+		return newInteger(int64(math.Pow(float64(leftVal), float64(rightVal))))
 	case "/":
 		x := float64(leftVal) / float64(rightVal)
 		if math.Mod(x, 1) == 0 {
-			return &object.Integer{Value: int64(x)}
+			return newInteger(int64(x))
 		} else {
 			return &object.Float{Value: x}
 		}
 	case "%":
-		return &object.Integer{Value: leftVal % rightVal}
+		if rightVal == 0 {
+			return newError("Mstari %d: Huwezi kugawanya kwa sifuri", line)
+		}
+		return newInteger(leftVal % rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case "<=":
@@ -479,6 +586,42 @@ func evalFloatIntegerInfixExpression(operator string, left, right object.Object,
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// evalFloorDivision always gives a NAMBA, rounding the quotient down towards
+// negative infinity, so -7 ~/ 2 is -4 rather than -3.
+func evalFloorDivision(left, right object.Object, line int) object.Object {
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		if r.Value == 0 {
+			return newError("Mstari %d: Huwezi kugawanya kwa sifuri", line)
+		}
+		if l.Value == math.MinInt64 && r.Value == -1 {
+			return newError("Mstari %d: Samahani, jibu la %s ~/ %s ni kubwa mno kuwa NAMBA", line, l.Inspect(), r.Inspect())
+		}
+		q := l.Value / r.Value
+		if l.Value%r.Value != 0 && (l.Value < 0) != (r.Value < 0) {
+			q--
+		}
+		return &object.Integer{Value: q}
+	}
+
+	leftVal, _ := numberToFloat(left)
+	rightVal, _ := numberToFloat(right)
+	if rightVal == 0 {
+		return newError("Mstari %d: Huwezi kugawanya kwa sifuri", line)
+	}
+	// the check is written so NaN fails it too
+	q := math.Floor(leftVal / rightVal)
+	if !(q >= math.MinInt64 && q < math.MaxInt64) {
+		return newError("Mstari %d: Samahani, jibu la %s ~/ %s ni kubwa mno kuwa NAMBA", line, left.Inspect(), right.Inspect())
+	}
+	return &object.Integer{Value: int64(q)}
+}
+
 func evalBooleanInfixExpression(operator string, left, right object.Object, line int) object.Object {
 	leftVal := left.(*object.Boolean).Value
 	rightVal := right.(*object.Boolean).Value
@@ -503,7 +646,7 @@ func evalPostfixExpression(env *object.Environment, operator string, node *ast.P
 		switch arg := val.(type) {
 		case *object.Integer:
 			v := arg.Value + 1
-			return env.Set(node.Token.Literal, &object.Integer{Value: v})
+			return env.Set(node.Token.Literal, newInteger(v))
 		case *object.Float:
 			v := arg.Value + 1
 			return env.Set(node.Token.Literal, &object.Float{Value: v})
@@ -515,7 +658,7 @@ func evalPostfixExpression(env *object.Environment, operator string, node *ast.P
 		switch arg := val.(type) {
 		case *object.Integer:
 			v := arg.Value - 1
-			return env.Set(node.Token.Literal, &object.Integer{Value: v})
+			return env.Set(node.Token.Literal, newInteger(v))
 		case *object.Float:
 			v := arg.Value - 1
 			return env.Set(node.Token.Literal, &object.Float{Value: v})
@@ -564,7 +707,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.CONTINUE_OBJ || rt == object.BREAK_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.CONTINUE_OBJ || rt == object.BREAK_OBJ || rt == object.YIELD_OBJ {
 				return result
 			}
 		}
@@ -574,7 +717,6 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 }
 
 func newError(format string, a ...interface{}) *object.Error {
-	format = fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, format)
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
@@ -590,7 +732,20 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
-	if builtin, ok := builtins[node.Value]; ok {
+	// the cache is only trusted when every builtin is allowed, since the
+	// same node may later run in a sandbox that hides the builtin
+	allowed := env.Builtins()
+	if builtin, ok := node.Builtin.Load().(*object.Builtin); ok && allowed == nil {
+		return builtin
+	}
+	available := builtins
+	if allowed != nil {
+		available = allowed
+	}
+	if builtin, ok := available[node.Value]; ok {
+		if allowed == nil {
+			node.Builtin.Store(builtin)
+		}
 		return builtin
 	}
 
@@ -617,9 +772,22 @@ func applyFunction(fn object.Object, args []object.Object, line int) object.Obje
 	case *object.Function:
 		extendedEnv := extendedFunctionEnv(fn, args)
 		evaluated := Eval(fn.Body, extendedEnv)
+		if err := loopControlError(evaluated); err != nil {
+			evaluated = err
+		}
+		// each call the error passes through on its way out adds itself,
+		// so the trace ends up innermost call first without keeping a
+		// call stack that two programs running at once would share
+		if err, ok := evaluated.(*object.Error); ok {
+			name := fn.Name
+			if name == "" {
+				name = "unda"
+			}
+			err.Trace = append(err.Trace, fmt.Sprintf("ndani ya %s(), iliyoitwa Mstari %d", name, line))
+		}
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
-		if result := fn.Fn(args...); result != nil {
+		if result := fn.Fn(line, args...); result != nil {
 			return result
 		}
 		return NULL
@@ -629,17 +797,71 @@ func applyFunction(fn object.Object, args []object.Object, line int) object.Obje
 
 }
 
+// calleeName is the text of what was called, eg mtu.jina for mtu.jina(),
+// without the brackets that String adds around compound expressions.
+func calleeName(node ast.Expression) string {
+	name := node.String()
+	if strings.HasPrefix(name, "(") && strings.HasSuffix(name, ")") {
+		name = name[1 : len(name)-1]
+	}
+	return name
+}
+
 func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
-	env := object.NewEnclosedEnvironment(fn.Env)
+	env := object.NewSizedEnclosedEnvironment(fn.Env, len(fn.Parameters))
 
 	for paramIdx, param := range fn.Parameters {
-		if paramIdx < len(args) {
+		// a nil argument is a parameter that a named call left out
+		if paramIdx < len(args) && args[paramIdx] != nil {
 			env.Set(param.Value, args[paramIdx])
 		}
 	}
 	return env
 }
 
+// bindNamedArguments puts each named argument in the slot of the
+// parameter with that name, after the positional arguments
+func bindNamedArguments(fn object.Object, args []object.Object, named []*ast.NamedArgument, env *object.Environment, line int) ([]object.Object, *object.Error) {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return nil, newError("Mstari %d: %s haikubali hoja zenye majina", line, fn.Type())
+	}
+	name := function.Name
+	if name == "" {
+		name = "unda"
+	}
+
+	bound := make([]object.Object, len(function.Parameters))
+	copy(bound, args)
+	if len(args) > len(bound) {
+		bound = args
+	}
+
+	for _, arg := range named {
+		idx := -1
+		for i, param := range function.Parameters {
+			if param.Value == arg.Name.Value {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, newError("Mstari %d: %s() haina hoja inayoitwa '%s'", line, name, arg.Name.Value)
+		}
+		if bound[idx] != nil {
+			return nil, newError("Mstari %d: Hoja '%s' imepewa thamani zaidi ya mara moja", line, arg.Name.Value)
+		}
+
+		value := Eval(arg.Value, env)
+		if isError(value) {
+			return nil, value.(*object.Error)
+		}
+		bound[idx] = value
+	}
+
+	return bound, nil
+}
+
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
 		return returnValue.Value
@@ -648,6 +870,45 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
+// repeatCount treats a negative repeat count as zero, so "ab" * -1 is ""
+func repeatCount(n int64) int {
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
+
+// maxRepeatLength is the most bytes a string made with * may have
+const maxRepeatLength = 100000000
+
+func repeatString(str string, count int64, line int) object.Object {
+	n := repeatCount(count)
+	if n > 0 && len(str) > maxRepeatLength/n {
+		return newError("Mstari %d: Samahani, neno hili ni refu mno, kikomo ni baiti %d", line, maxRepeatLength)
+	}
+	return &object.String{Value: strings.Repeat(str, n)}
+}
+
+// maxRepeatElements is the most elements an array made with * may have
+const maxRepeatElements = 10000000
+
+// repeatArray always builds a new array, so changing the result never
+// changes the array it was made from
+func repeatArray(array *object.Array, count int64, line int) object.Object {
+	n := repeatCount(count)
+	if len(array.Elements) == 0 {
+		n = 0
+	}
+	if n > 0 && len(array.Elements) > maxRepeatElements/n {
+		return newError("Mstari %d: Samahani, orodha hii ni ndefu mno, kikomo ni vitu %d", line, maxRepeatElements)
+	}
+	elements := make([]object.Object, 0, len(array.Elements)*n)
+	for i := 0; i < n; i++ {
+		elements = append(elements, array.Elements...)
+	}
+	return &object.Array{Elements: elements}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object, line int) object.Object {
 
 	leftVal := left.(*object.String).Value
@@ -690,10 +951,265 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObject.Elements[idx]
 }
 
+func evalSliceExpression(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	if node.Step != nil {
+		return evalSteppedSlice(node, left, env)
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		start, end, err := evalSliceBounds(node, len(left.Elements), env)
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, end-start)
+		copy(elements, left.Elements[start:end])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		start, end, err := evalSliceBounds(node, len(runes), env)
+		if err != nil {
+			return err
+		}
+		return &object.String{Value: string(runes[start:end])}
+	default:
+		return newError("Mstari %d: Huwezi kukata %s", node.Token.Line, left.Type())
+	}
+}
+
+// evalSliceBounds works out the [start:end] range of a slice over a
+// sequence of the given length. Missing bounds mean the start or end of
+// the sequence, negative bounds count from the end and bounds that are
+// out of range are clamped, so the result is always safe to slice with.
+func evalSliceBounds(node *ast.SliceExpression, length int, env *object.Environment) (int, int, *object.Error) {
+	bound := func(exp ast.Expression, fallback int) (int, *object.Error) {
+		if exp == nil {
+			return fallback, nil
+		}
+		obj := Eval(exp, env)
+		if err, ok := obj.(*object.Error); ok {
+			return 0, err
+		}
+		i, ok := obj.(*object.Integer)
+		if !ok {
+			return 0, newError("Mstari %d: Tafadhali tumia number, sio: %s", node.Token.Line, obj.Type())
+		}
+		idx := int(i.Value)
+		if idx < 0 {
+			idx += length
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		if idx > length {
+			idx = length
+		}
+		return idx, nil
+	}
+
+	start, err := bound(node.Start, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := bound(node.End, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
+
+// evalSteppedSlice handles a[start:end:step]. A negative step walks
+// backwards, so the missing bounds then default to the end and the start
+// of the sequence, eg a[::-1] is a reversed copy of a.
+func evalSteppedSlice(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		indexes, err := evalSliceIndexes(node, len(left.Elements), env)
+		if err != nil {
+			return err
+		}
+		elements := make([]object.Object, len(indexes))
+		for i, idx := range indexes {
+			elements[i] = left.Elements[idx]
+		}
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		indexes, err := evalSliceIndexes(node, len(runes), env)
+		if err != nil {
+			return err
+		}
+		picked := make([]rune, len(indexes))
+		for i, idx := range indexes {
+			picked[i] = runes[idx]
+		}
+		return &object.String{Value: string(picked)}
+	default:
+		return newError("Mstari %d: Huwezi kukata %s", node.Token.Line, left.Type())
+	}
+}
+
+// evalSliceIndexes lists the positions a stepped slice picks from a
+// sequence of the given length. Bounds are clamped like in
+// evalSliceBounds, but a negative step may run down to just before the
+// first element.
+func evalSliceIndexes(node *ast.SliceExpression, length int, env *object.Environment) ([]int, *object.Error) {
+	integer := func(exp ast.Expression) (int, *object.Error) {
+		obj := Eval(exp, env)
+		if err, ok := obj.(*object.Error); ok {
+			return 0, err
+		}
+		i, ok := obj.(*object.Integer)
+		if !ok {
+			return 0, newError("Mstari %d: Tafadhali tumia number, sio: %s", node.Token.Line, obj.Type())
+		}
+		return int(i.Value), nil
+	}
+
+	step, err := integer(node.Step)
+	if err != nil {
+		return nil, err
+	}
+	if step == 0 {
+		return nil, newError("Mstari %d: Hatua ya kipande haiwezi kuwa 0", node.Token.Line)
+	}
+
+	// lowest and highest are the furthest a bound can reach in the
+	// direction of the step
+	lowest, highest := 0, length
+	if step < 0 {
+		lowest, highest = -1, length-1
+	}
+	bound := func(exp ast.Expression, fallback int) (int, *object.Error) {
+		if exp == nil {
+			return fallback, nil
+		}
+		idx, err := integer(exp)
+		if err != nil {
+			return 0, err
+		}
+		if idx < 0 {
+			idx += length
+		}
+		if idx < lowest {
+			idx = lowest
+		}
+		if idx > highest {
+			idx = highest
+		}
+		return idx, nil
+	}
+
+	startFallback, endFallback := lowest, highest
+	if step < 0 {
+		startFallback, endFallback = highest, lowest
+	}
+	start, err := bound(node.Start, startFallback)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(node.End, endFallback)
+	if err != nil {
+		return nil, err
+	}
+
+	// the distance left to end is checked before stepping, since a huge
+	// step would overflow i
+	indexes := []int{}
+	if step > 0 {
+		for i := start; i < end; i += step {
+			indexes = append(indexes, i)
+			if step >= end-i {
+				break
+			}
+		}
+	} else {
+		for i := start; i > end; i += step {
+			indexes = append(indexes, i)
+			if step <= end-i {
+				break
+			}
+		}
+	}
+	return indexes, nil
+}
+
+// assignSlice replaces the elements in the sliced range of an array with
+// the elements of value, growing or shrinking the array as needed.
+func assignSlice(node *ast.SliceExpression, obj, value object.Object, env *object.Environment) *object.Error {
+	array, ok := obj.(*object.Array)
+	if !ok {
+		return newError("Mstari %d: Huwezi kubadilisha kipande cha %s", node.Token.Line, obj.Type())
+	}
+	replacement, ok := value.(*object.Array)
+	if !ok {
+		return newError("Mstari %d: Kipande kinaweza kubadilishwa na ORODHA tu, sio %s", node.Token.Line, value.Type())
+	}
+	if node.Step != nil {
+		return newError("Mstari %d: Huwezi kubadilisha kipande chenye hatua", node.Token.Line)
+	}
+	start, end, err := evalSliceBounds(node, len(array.Elements), env)
+	if err != nil {
+		return err
+	}
+
+	elements := make([]object.Object, 0, len(array.Elements)-(end-start)+len(replacement.Elements))
+	elements = append(elements, array.Elements[:start]...)
+	elements = append(elements, replacement.Elements...)
+	elements = append(elements, array.Elements[end:]...)
+	array.Elements = elements
+	return nil
+}
+
+// assignIndex stores value at ie.Index inside the array or dict that
+// ie.Left evaluates to. ie.Left may itself be an index expression, eg
+// m[1][2] = 5, in which case the inner container is found first.
+func assignIndex(ie *ast.IndexExpression, value object.Object, env *object.Environment) *object.Error {
+	line := ie.Token.Line
+	obj := Eval(ie.Left, env)
+	if err, ok := obj.(*object.Error); ok {
+		return err
+	}
+	index := Eval(ie.Index, env)
+	if err, ok := index.(*object.Error); ok {
+		return err
+	}
+	return setIndex(obj, index, value, line)
+}
+
+// setIndex stores value at index in an array or dict. Arrays only accept
+// indexes that already exist.
+func setIndex(obj, index, value object.Object, line int) *object.Error {
+	switch container := obj.(type) {
+	case *object.Array:
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("Mstari %d: Tafadhali tumia number, sio: %s", line, index.Type())
+		}
+		if idx.Value < 0 || idx.Value >= int64(len(container.Elements)) {
+			return newError("Mstari %d: Index imezidi idadi ya elements", line)
+		}
+		container.Elements[idx.Value] = value
+	case *object.Dict:
+		hashKey, ok := index.(object.Hashable)
+		if !ok {
+			return newError("Mstari %d: Samahani, %s haitumiki kama key", line, index.Type())
+		}
+		container.Set(hashKey.HashKey(), object.DictPair{Key: index, Value: value})
+	default:
+		return newError("Mstari %d: Operesheni hii haiwezekani kwa: %s", line, obj.Type())
+	}
+	return nil
+}
+
 func evalDictLiteral(node *ast.DictLiteral, env *object.Environment) object.Object {
-	pairs := make(map[object.HashKey]object.DictPair)
+	dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
 
-	for keyNode, valueNode := range node.Pairs {
+	for _, keyNode := range node.Keys {
+		valueNode := node.Pairs[keyNode]
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
@@ -710,10 +1226,10 @@ func evalDictLiteral(node *ast.DictLiteral, env *object.Environment) object.Obje
 		}
 
 		hashed := hashKey.HashKey()
-		pairs[hashed] = object.DictPair{Key: key, Value: value}
+		dict.Set(hashed, object.DictPair{Key: key, Value: value})
 	}
 
-	return &object.Dict{Pairs: pairs}
+	return dict
 }
 
 func evalDictIndexExpression(dict, index object.Object, line int) object.Object {
@@ -732,6 +1248,46 @@ func evalDictIndexExpression(dict, index object.Object, line int) object.Object
 	return pair.Value
 }
 
+// evalPropertyExpression looks up obj.name. A missing field on a dict
+// gives tupu, just like indexing with a missing key.
+func evalPropertyExpression(obj object.Object, name string, line int) object.Object {
+	switch obj := obj.(type) {
+	case *object.Dict:
+		pair, ok := obj.Pairs[(&object.String{Value: name}).HashKey()]
+		if !ok {
+			return NULL
+		}
+		return pair.Value
+	case *object.Builder:
+		return builderMethod(obj, name, line)
+	default:
+		return newError("Mstari %d: Huwezi kutumia '.%s' na %s", line, name, obj.Type())
+	}
+}
+
+// builderMethod returns the method of a mjengo bound to it, so that
+// mjengo.ongeza(x) is a normal call.
+func builderMethod(b *object.Builder, name string, line int) object.Object {
+	switch name {
+	case "ongeza":
+		return &object.Builtin{Fn: func(line int, args ...object.Object) object.Object {
+			for _, arg := range args {
+				b.Add(arg)
+			}
+			return b
+		}}
+	case "matokeo":
+		return &object.Builtin{Fn: func(line int, args ...object.Object) object.Object {
+			if len(args) != 0 {
+				return newError("Mstari %d: Samahani, matokeo haihitaji hoja, wewe umeweka %d", line, len(args))
+			}
+			return b.Result()
+		}}
+	default:
+		return newError("Mstari %d: Mjengo hauna '.%s', tumia .ongeza() au .matokeo()", line, name)
+	}
+}
+
 func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) object.Object {
 	condition := Eval(we.Condition, env)
 	if isError(condition) {
@@ -745,17 +1301,69 @@ func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) objec
 		if evaluated != nil && evaluated.Type() == object.BREAK_OBJ {
 			return evaluated
 		}
-		evalWhileExpression(we, env)
+		if evaluated != nil && evaluated.Type() == object.YIELD_OBJ {
+			return evaluated
+		}
+		if rest := evalWhileExpression(we, env); rest.Type() == object.YIELD_OBJ {
+			return rest
+		}
 	}
 	return NULL
 }
 
 func evalBreak(node *ast.Break) object.Object {
-	return BREAK
+	return &object.Break{Line: node.Token.Line}
+}
+
+// evalBlockExpression runs the statements of a kizuizi. Its value is the
+// one given to toa, or that of the last statement if toa is never reached.
+// Only toa can leave the block: a rudisha, vunja or endelea that gets out
+// of it is an error, since the value is usually headed for an assignment.
+func evalBlockExpression(be *ast.BlockExpression, env *object.Environment) object.Object {
+	result := Eval(be.Block, env)
+	switch result := result.(type) {
+	case nil:
+		return NULL
+	case *object.Yield:
+		return result.Value
+	case *object.ReturnValue:
+		return newError("Mstari %d: 'rudisha' haiwezi kutoka nje ya kizuizi, tumia 'toa'", be.Token.Line)
+	case *object.Break:
+		return newError("Mstari %d: 'vunja' haiwezi kutoka nje ya kizuizi, tumia 'toa'", result.Line)
+	case *object.Continue:
+		return newError("Mstari %d: 'endelea' haiwezi kutoka nje ya kizuizi, tumia 'toa'", result.Line)
+	}
+	return result
+}
+
+func evalYieldStatement(node *ast.YieldStatement, env *object.Environment) object.Object {
+	if node.Value == nil {
+		return &object.Yield{Value: NULL, Line: node.Token.Line}
+	}
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+	return &object.Yield{Value: val, Line: node.Token.Line}
 }
 
 func evalContinue(node *ast.Continue) object.Object {
-	return CONTINUE
+	return &object.Continue{Line: node.Token.Line}
+}
+
+// loopControlError turns a vunja or endelea that escaped every loop, or a
+// toa that escaped every kizuizi, into an error, or returns nil for
+// anything else
+func loopControlError(obj object.Object) *object.Error {
+	switch obj := obj.(type) {
+	case *object.Break:
+		return newError("Mstari %d: 'vunja' nje ya kitanzi", obj.Line)
+	case *object.Continue:
+		return newError("Mstari %d: 'endelea' nje ya kitanzi", obj.Line)
+	case *object.Yield:
+		return newError("Mstari %d: 'toa' nje ya kizuizi", obj.Line)
+	}
+	return nil
 }
 
 func evalInExpression(left, right object.Object, line int) object.Object {
@@ -766,6 +1374,9 @@ func evalInExpression(left, right object.Object, line int) object.Object {
 		return evalInArrayExpression(left, right)
 	case *object.Dict:
 		return evalInDictExpression(left, right, line)
+	case *object.Set:
+		hashable, ok := left.(object.Hashable)
+		return nativeBoolToBooleanObject(ok && right.(*object.Set).Has(hashable))
 	default:
 		return FALSE
 	}
@@ -810,22 +1421,11 @@ func evalInArrayExpression(left, right object.Object) object.Object {
 				}
 			}
 		}
-	case *object.Integer:
-		for _, v := range rightVal.Elements {
-			if v.Type() == object.INTEGER_OBJ {
-				elem := v.(*object.Integer)
-				if elem.Value == leftVal.Value {
-					return TRUE
-				}
-			}
-		}
-	case *object.Float:
+	case *object.Integer, *object.Float:
+		// numbers match across NAMBA and DESIMALI the same way == does
 		for _, v := range rightVal.Elements {
-			if v.Type() == object.FLOAT_OBJ {
-				elem := v.(*object.Float)
-				if elem.Value == leftVal.Value {
-					return TRUE
-				}
+			if isNumber(v) && evalInfixExpression("==", left, v, 0) == TRUE {
+				return TRUE
 			}
 		}
 	}
@@ -887,6 +1487,12 @@ func evalForInExpression(fie *ast.ForIn, env *object.Environment, line int) obje
 	iterable := Eval(fie.Iterable, env)
 	existingKeyIdentifier, okk := env.Get(fie.Key) // again, stay safe
 	existingValueIdentifier, okv := env.Get(fie.Value)
+	existingPatternIdentifiers := make(map[string]object.Object, len(fie.Pattern))
+	for _, name := range fie.Pattern {
+		if existing, ok := env.Get(name); ok {
+			existingPatternIdentifiers[name] = existing
+		}
+	}
 	defer func() { // restore them later on
 		if okk {
 			env.Set(fie.Key, existingKeyIdentifier)
@@ -894,23 +1500,44 @@ func evalForInExpression(fie *ast.ForIn, env *object.Environment, line int) obje
 		if okv {
 			env.Set(fie.Value, existingValueIdentifier)
 		}
+		for name, existing := range existingPatternIdentifiers {
+			env.Set(name, existing)
+		}
 	}()
 	switch i := iterable.(type) {
 	case object.Iterable:
 		defer func() {
 			i.Reset()
 		}()
-		return loopIterable(i.Next, env, fie)
+		return loopIterable(loopNext(i, fie.Key == ""), env, fie)
 	default:
 		return newError("Mstari %d: Huwezi kufanya operesheni hii na %s", line, i.Type())
 	}
 }
 
+// loopNext returns the function that steps through a loop. A dict looped
+// over with a single variable gives its keys, everything else gives values.
+func loopNext(i object.Iterable, single bool) func() (object.Object, object.Object) {
+	if _, ok := i.(*object.Dict); ok && single {
+		return func() (object.Object, object.Object) {
+			k, _ := i.Next()
+			return k, k
+		}
+	}
+	return i.Next
+}
+
 func loopIterable(next func() (object.Object, object.Object), env *object.Environment, fi *ast.ForIn) object.Object {
 	k, v := next()
 	for k != nil && v != nil {
 		env.Set(fi.Key, k)
-		env.Set(fi.Value, v)
+		if fi.Pattern != nil {
+			if err := destructure(fi, v, env); err != nil {
+				return err
+			}
+		} else {
+			env.Set(fi.Value, v)
+		}
 		res := Eval(fi.Block, env)
 		if isError(res) {
 			return res
@@ -923,7 +1550,7 @@ func loopIterable(next func() (object.Object, object.Object), env *object.Enviro
 				k, v = next()
 				continue
 			}
-			if res.Type() == object.RETURN_VALUE_OBJ {
+			if res.Type() == object.RETURN_VALUE_OBJ || res.Type() == object.YIELD_OBJ {
 				return res
 			}
 		}
@@ -932,6 +1559,105 @@ func loopIterable(next func() (object.Object, object.Object), env *object.Enviro
 	return NULL
 }
 
+// destructure binds each element of the array v to the matching name in
+// the loop's pattern, eg kwa [jina, umri] ktk watu
+func destructure(fi *ast.ForIn, v object.Object, env *object.Environment) *object.Error {
+	arr, ok := v.(*object.Array)
+	if !ok {
+		return newError("Mstari %d: Samahani, [%s] inahitaji ORODHA, sio %s", fi.Token.Line, strings.Join(fi.Pattern, ", "), v.Type())
+	}
+	if len(arr.Elements) != len(fi.Pattern) {
+		return newError("Mstari %d: Samahani, [%s] inahitaji vitu %d, lakini orodha ina vitu %d",
+			fi.Token.Line, strings.Join(fi.Pattern, ", "), len(fi.Pattern), len(arr.Elements))
+	}
+	for i, name := range fi.Pattern {
+		env.Set(name, arr.Elements[i])
+	}
+	return nil
+}
+
+func evalComprehension(node *ast.Comprehension, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+	i, ok := iterable.(object.Iterable)
+	if !ok {
+		return newError("Mstari %d: Huwezi kufanya operesheni hii na %s", node.Token.Line, iterable.Type())
+	}
+	defer i.Reset()
+
+	// the loop variables live in their own scope so they don't leak out
+	loopEnv := object.NewEnclosedEnvironment(env)
+	elements := []object.Object{}
+	dict := &object.Dict{Pairs: make(map[object.HashKey]object.DictPair)}
+
+	next := loopNext(i, node.KeyName == "")
+	for k, v := next(); k != nil && v != nil; k, v = next() {
+		if node.KeyName != "" {
+			loopEnv.Set(node.KeyName, k)
+		}
+		loopEnv.Set(node.ValueName, v)
+
+		if node.Condition != nil {
+			condition := Eval(node.Condition, loopEnv)
+			if isError(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				continue
+			}
+		}
+
+		if node.Key == nil {
+			value := Eval(node.Value, loopEnv)
+			if isError(value) {
+				return value
+			}
+			elements = append(elements, value)
+			continue
+		}
+
+		key := Eval(node.Key, loopEnv)
+		if isError(key) {
+			return key
+		}
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("Mstari %d: Hashing imeshindikana: %s", node.Token.Line, key.Type())
+		}
+		value := Eval(node.Value, loopEnv)
+		if isError(value) {
+			return value
+		}
+		dict.Set(hashKey.HashKey(), object.DictPair{Key: key, Value: value})
+	}
+
+	if node.Key == nil {
+		return &object.Array{Elements: elements}
+	}
+	return dict
+}
+
+// evalCaseCondition checks a guarded case (ikiwa kama x > 5) or a
+// comparison case (ikiwa < 10) against the switch value
+func evalCaseCondition(opt *ast.CaseExpression, value object.Object, env *object.Environment) (bool, object.Object) {
+	var result object.Object
+	if opt.Guard != nil {
+		result = Eval(opt.Guard, env)
+	} else {
+		right := Eval(opt.Expr[0], env)
+		if isError(right) {
+			return false, right
+		}
+		result = evalInfixExpression(opt.Operator, value, right, opt.Token.Line)
+	}
+	if isError(result) {
+		return false, result
+	}
+	return isTruthy(result), nil
+}
+
 func evalSwitchStatement(se *ast.SwitchExpression, env *object.Environment) object.Object {
 	obj := Eval(se.Value, env)
 	for _, opt := range se.Choices {
@@ -939,6 +1665,16 @@ func evalSwitchStatement(se *ast.SwitchExpression, env *object.Environment) obje
 		if opt.Default {
 			continue
 		}
+		if opt.Guard != nil || opt.Operator != "" {
+			matched, err := evalCaseCondition(opt, obj, env)
+			if err != nil {
+				return err
+			}
+			if matched {
+				return evalBlockStatement(opt.Block, env)
+			}
+			continue
+		}
 		for _, val := range opt.Expr {
 			out := Eval(val, env)
 			if obj.Type() == out.Type() && obj.Inspect() == out.Inspect() {
@@ -1,9 +1,20 @@
 package evaluator
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/AvicennaJr/Nuru/ast"
 	"github.com/AvicennaJr/Nuru/lexer"
 	"github.com/AvicennaJr/Nuru/object"
 	"github.com/AvicennaJr/Nuru/parser"
@@ -145,6 +156,11 @@ func TestIfElseExpressions(t *testing.T) {
 		{"kama (1 > 2) {10}", nil},
 		{"kama (1 > 2) {10} sivyo {20}", 20},
 		{"kama (1 < 2) {10} sivyo {20}", 10},
+		{"fanya x = 5; kama (x < 0) {1} au kama (x < 10) {2} sivyo {3}", 2},
+		{"fanya x = -5; kama (x < 0) {1} au kama (x < 10) {2} sivyo {3}", 1},
+		{"fanya x = 50; kama (x < 0) {1} au kama (x < 10) {2} sivyo {3}", 3},
+		{"fanya x = 50; kama (x < 0) {1} au kama (x < 10) {2}", nil},
+		{"fanya x = 50; kama (x < 0) {1} sivyo kama (x < 100) {2} sivyo {3}", 2},
 	}
 
 	for _, tt := range tests {
@@ -158,6 +174,59 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
+func TestIfExpressionAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fanya x = kama (kweli) { 1 } sivyo { 2 }; x", 1},
+		{"fanya x = kama (sikweli) { 1 } sivyo { 2 }; x", 2},
+		{"fanya x = kama (sikweli) { 1 }; x", nil},
+		{"fanya x = 0; x = kama (3 > 2) { 10 } sivyo { 20 }; x", 10},
+		{"fanya x = kama (kweli) { fanya y = 4; y * 2 } sivyo { 0 }; x", 8},
+		{"fanya x = kama (sikweli) { 1 } au kama (kweli) { 2 } sivyo { 3 }; x", 2},
+		{"fanya x = (kama (kweli) { 5 } sivyo { 6 }) + 1; x", 6},
+		{"fanya f = unda(n) { kama (n > 0) { n } sivyo { -n } }; f(-7)", 7},
+		{"fanya x = kama (kweli) { kama (sikweli) { 1 } }; x", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestElseIfShortCircuits(t *testing.T) {
+	input := `
+	fanya ukaguzi = {"idadi": 0}
+	fanya angalia = unda(jibu) {
+		ukaguzi["idadi"] += 1
+		rudisha jibu
+	}
+	fanya tawi = kama (angalia(sikweli)) {
+		"kwanza"
+	} au kama (angalia(kweli)) {
+		"pili"
+	} au kama (angalia(kweli)) {
+		"tatu"
+	} sivyo {
+		"mwisho"
+	}
+	fanya jibu = [tawi, ukaguzi["idadi"]]
+	jibu
+	`
+
+	evaluated := testEval(input)
+	if evaluated.Inspect() != "[pili, 2]" {
+		t.Errorf("expected the middle branch after two checks, got=%q", evaluated.Inspect())
+	}
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
 		t.Errorf("object is not null, got=%T(+%v)", obj, obj)
@@ -247,8 +316,8 @@ kama (10 > 1) {
 			continue
 		}
 
-		if errObj.Message != fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, tt.expectedMessage) {
-			t.Errorf("wrong error message, expected=%q, got=%q", fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, tt.expectedMessage), errObj.Message)
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message, expected=%q, got=%q", tt.expectedMessage, errObj.Message)
 		}
 	}
 }
@@ -360,15 +429,17 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`idadi("")`, 0},
 		{`idadi("four")`, 4},
 		{`idadi("hello world")`, 11},
-		{`idadi(1)`, "Samahani, hii function haitumiki na NAMBA"},
-		{`idadi("one", "two")`, "Hoja hazilingani, tunahitaji=1, tumepewa=2"},
-		{`jumla()`, "Hoja hazilingani, tunahitaji=1, tumepewa=0"},
-		{`jumla("")`, "Samahani, hii function haitumiki na NENO"},
-		{`jumla(1)`, "Samahani, hii function haitumiki na NAMBA"},
+		{`idadi(1)`, "Mstari 0: Samahani, hii function haitumiki na NAMBA"},
+		{`idadi("one", "two")`, "Mstari 0: Hoja hazilingani, tunahitaji=1, tumepewa=2"},
+		{`jumla()`, "Mstari 0: Hoja hazilingani, tunahitaji=1, tumepewa=0"},
+		{`jumla("")`, "Mstari 0: Samahani, hii function haitumiki na NENO"},
+		{`jumla(1)`, "Mstari 0: Samahani, hii function haitumiki na NAMBA"},
 		{`jumla([1,2,3])`, 6},
 		{`jumla([1,2,3.4])`, 6.4},
 		{`jumla([1.1,2.5,3.4])`, 7},
-		{`jumla([1.1,2.5,"q"])`, "Samahani namba tu zinahitajika"},
+		{`jumla([1.1,2.5,"q"])`, "Mstari 0: Samahani namba tu zinahitajika"},
+		{"fanya x = 1\n\nidadi(x)", "Mstari 2: Samahani, hii function haitumiki na NAMBA"},
+		{"fanya f = unda() {\n\tidadi(5, 6)\n}\nf()", "Mstari 1: Hoja hazilingani, tunahitaji=1, tumepewa=2"},
 	}
 
 	for _, tt := range tests {
@@ -386,7 +457,7 @@ func TestBuiltinFunctions(t *testing.T) {
 				t.Errorf("Object is not Error, got=%T(%+v)", evaluated, evaluated)
 				continue
 			}
-			if errObj.Message != fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, expected) {
+			if errObj.Message != expected {
 				t.Errorf("Wrong eror message, expected=%q, got=%q", expected, errObj.Message)
 			}
 		}
@@ -542,3 +613,3435 @@ func TestDictIndexExpression(t *testing.T) {
 		}
 	}
 }
+
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String, got=%T(%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value, got=%q, want=%q", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+// errorMessage marks an expected value in a table test as an error message
+// rather than a string result.
+type errorMessage string
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
+	if !ok {
+		t.Errorf("object is not Error, got=%T(%+v)", obj, obj)
+		return false
+	}
+
+	if errObj.Message != expected {
+		t.Errorf("wrong error message, expected=%q, got=%q", expected, errObj.Message)
+		return false
+	}
+
+	return true
+}
+
+func TestBaseFormattingBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`kwaBinari(5)`, "101"},
+		{`kwaBinari(0)`, "0"},
+		{`kwaBinari(-5)`, "-101"},
+		{`kwaOktali(64)`, "100"},
+		{`kwaOktali(-8)`, "-10"},
+		{`kwaHex(255)`, "ff"},
+		{`kwaHex(-255)`, "-ff"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBaseParsingBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`kutokaMsingi(kwaBinari(1234), 2)`, 1234},
+		{`kutokaMsingi(kwaOktali(1234), 8)`, 1234},
+		{`kutokaMsingi(kwaHex(1234), 16)`, 1234},
+		{`kutokaMsingi(kwaBinari(-42), 2)`, -42},
+		{`kutokaMsingi(kwaHex(-42), 16)`, -42},
+		{`kutokaMsingi("FF", 16)`, 255},
+		{`kutokaMsingi("102", 2)`, `Mstari 0: Samahani, "102" sio namba sahihi ya msingi 2`},
+		{`kutokaMsingi("10", 1)`, "Mstari 0: Samahani, msingi lazima uwe kati ya 2 na 36, sio 1"},
+		{`kwaHex("10")`, "Mstari 0: Samahani, hii function haitumiki na NENO"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestClampAndLerpBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`bana(-5, 0, 10)`, 0},
+		{`bana(15, 0, 10)`, 10},
+		{`bana(5, 0, 10)`, 5},
+		{`bana(1.5, 0, 1)`, 1.0},
+		{`bana(0.25, 0, 1)`, 0.25},
+		{`bana(5, 10, 0)`, "Mstari 0: Samahani, chini (10) haiwezi kuzidi juu (0)"},
+		{`bana("5", 0, 10)`, "Mstari 0: Samahani, namba tu zinahitajika, sio NENO"},
+		{`kati(0, 10, 0.5)`, 5.0},
+		{`kati(2.0, 4.0, 0.5)`, 3.0},
+		{`kati(0, 10, 1)`, 10},
+		{`kati(0, 10)`, "Mstari 0: Samahani, tunahitaji Hoja 3, wewe umeweka 2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestCaseInsensitiveCompareBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`linganishaBila("nuru", "nuru")`, true},
+		{`linganishaBila("Nuru", "nURU")`, true},
+		{`linganishaBila("nuru", "nura")`, false},
+		{`linganishaBila("ÇAĞRI", "çağri")`, true},
+		{`linganishaBila("Straße", "STRASSE")`, false},
+		{`linganishaBila("ΣΟΦΙΑ", "σοφια")`, true},
+		{`linganishaBila("nuru", 1)`, "Mstari 0: Samahani, hii function inalinganisha NENO tu, sio NENO na NAMBA"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestSelectByKeyBuiltins(t *testing.T) {
+	people := `fanya watu = [
+		{"jina": "Asha", "umri": 30},
+		{"jina": "Juma", "umri": 45},
+		{"jina": "Neema", "umri": 19},
+		{"jina": "Baraka", "umri": 45}
+	];
+	fanya umri = unda(mtu) { mtu["umri"] };`
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{people + `kubwaKwa(watu, umri)["jina"]`, "Juma"},
+		{people + `ndogoKwa(watu, umri)["jina"]`, "Neema"},
+		{`kubwaKwa([3, -7, 5], unda(x) { x * x })`, -7},
+		{`ndogoKwa([2, 1.5, 3], unda(x) { x })`, 1.5},
+		{`kubwaKwa([], unda(x) { x })`, errorMessage("Mstari 0: Samahani, orodha haina kitu")},
+		{`kubwaKwa(1, unda(x) { x })`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe ORODHA, sio NAMBA")},
+		{"\nkubwaKwa([1, \"a\"], unda(x) { x })", errorMessage("Mstari 1: Aina Hazilingani: NENO > NAMBA")},
+		{"\n\nndogoKwa([1, \"a\"], unda(x) { x })", errorMessage("Mstari 2: Aina Hazilingani: NENO < NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestGroupByBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[object.HashKey]string
+	}{
+		{
+			`kundi([1, 2, 3, 4, 5, 6], unda(x) { x % 2 == 0 })`,
+			map[object.HashKey]string{
+				TRUE.HashKey():  "[2, 4, 6]",
+				FALSE.HashKey(): "[1, 3, 5]",
+			},
+		},
+		{
+			`fanya herufiYaKwanza = unda(s) { kwa c ktk s { rudisha c } };
+			kundi(["asha", "baraka", "amina", "bakari", "chausiku"], herufiYaKwanza)`,
+			map[object.HashKey]string{
+				(&object.String{Value: "a"}).HashKey(): "[asha, amina]",
+				(&object.String{Value: "b"}).HashKey(): "[baraka, bakari]",
+				(&object.String{Value: "c"}).HashKey(): "[chausiku]",
+			},
+		},
+		{
+			`kundi([], unda(x) { x })`,
+			map[object.HashKey]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		dict, ok := evaluated.(*object.Dict)
+		if !ok {
+			t.Fatalf("object is not Dict, got=%T(%+v)", evaluated, evaluated)
+		}
+		if len(dict.Pairs) != len(tt.expected) {
+			t.Errorf("dict has wrong number of groups, got=%d, want=%d", len(dict.Pairs), len(tt.expected))
+		}
+		for key, expected := range tt.expected {
+			pair, ok := dict.Pairs[key]
+			if !ok {
+				t.Errorf("no group for key %+v", key)
+				continue
+			}
+			if pair.Value.Inspect() != expected {
+				t.Errorf("group has wrong elements, got=%s, want=%s", pair.Value.Inspect(), expected)
+			}
+		}
+	}
+
+	testErrorObject(t, testEval(`kundi([1], unda(x) { [x] })`), "Mstari 0: Samahani, ORODHA haitumiki kama key")
+}
+
+func TestDictInspectIsStable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"z": 1, "a": 2, "m": 3}`, "{z: 1, a: 2, m: 3}"},
+		{`fanya d = {"z": 1}; d["a"] = 2; d["z"] = 3; d`, "{z: 3, a: 2}"},
+		{`{"b": 1, "a": 2} + {"c": 3, "b": 4}`, "{b: 4, a: 2, c: 3}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		for i := 0; i < 10; i++ {
+			if evaluated.Inspect() != tt.expected {
+				t.Fatalf("dict printed wrongly on call %d, got=%q, want=%q", i, evaluated.Inspect(), tt.expected)
+			}
+		}
+	}
+}
+
+func TestSequenceBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`mfululizo(0.0, 1.0, 0.5)`, "[0, 0.5]"},
+		{`mfululizo(0.0, 1.01, 0.5)`, "[0, 0.5, 1]"},
+		{`mfululizo(0, 1, 0.1)`, "[0, 0.1, 0.2, 0.30000000000000004, 0.4, 0.5, 0.6000000000000001, 0.7000000000000001, 0.8, 0.9]"},
+		{`mfululizo(0, 0.3, 0.1)`, "[0, 0.1, 0.2]"},
+		{`mfululizo(1.0, 0.0, -0.25)`, "[1, 0.75, 0.5, 0.25]"},
+		{`mfululizo(0, 5)`, "[0, 1, 2, 3, 4]"},
+		{`mfululizo(10, 0, -3)`, "[10, 7, 4, 1]"},
+		{`mfululizo(0, 1, -0.5)`, "[]"},
+		{`mfululizo(5, 0)`, "[]"},
+		{`mfululizo(0, 0)`, "[]"},
+		{`mfululizo(0, 1, -1)`, "[]"},
+		{`mfululizo(9223372036854775806, 9223372036854775807)`, "[9223372036854775806]"},
+		{`mfululizo(-9223372036854775807 - 1, 9223372036854775807, 9223372036854775807)`, "[-9223372036854775808, -1, 9223372036854775806]"},
+		{`mfululizo(9223372036854775807, -9223372036854775807 - 1, -9223372036854775807 - 1)`, "[9223372036854775807, -1]"},
+		{`mfululizo(0, 9223372036854775807)`, errorMessage("Mstari 0: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu 10000000")},
+		{`mfululizo(0, 10000001)`, errorMessage("Mstari 0: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu 10000000")},
+		{`mfululizo(0.0, 100000000.5)`, errorMessage("Mstari 0: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu 10000000")},
+		{`mfululizo(0.0, 0.0 / 0.0)`, errorMessage("Mstari 0: Samahani, mfululizo huu ni mrefu mno, kikomo ni vitu 10000000")},
+		{`mfululizo(0, 1, 0)`, errorMessage("Mstari 0: Samahani, hatua haiwezi kuwa sifuri")},
+		{`mfululizo(0, "1", 1)`, errorMessage("Mstari 0: Samahani, namba tu zinahitajika, sio NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("wrong sequence, got=%s, want=%s", evaluated.Inspect(), expected)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	// every element is computed from the start, so the count of a long
+	// sequence is exact and the last element doesn't drift
+	evaluated := testEval(`mfululizo(0, 100, 0.1)`)
+	arr, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array, got=%T(%+v)", evaluated, evaluated)
+	}
+	if len(arr.Elements) != 1000 {
+		t.Fatalf("wrong number of elements, got=%d, want=1000", len(arr.Elements))
+	}
+	testFloatObject(t, arr.Elements[999], 999*0.1)
+}
+
+func TestFunctionInspectAndIdentity(t *testing.T) {
+	inspects := []struct {
+		input    string
+		expected string
+	}{
+		{`unda() { 1 }`, "unda() {...}"},
+		{`unda(a, b) { a + b }`, "unda(a, b) {...}"},
+		{`fanya f = unda(x) { x }; [f]`, "[unda(x) {...}]"},
+	}
+
+	for _, tt := range inspects {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("function printed wrongly, got=%q, want=%q", evaluated.Inspect(), tt.expected)
+		}
+	}
+
+	comparisons := []struct {
+		input    string
+		expected bool
+	}{
+		{`fanya f = unda(x) { x }; f == f`, true},
+		{`fanya f = unda(x) { x }; fanya g = f; f == g`, true},
+		{`fanya f = unda(x) { x }; f != f`, false},
+		{`unda(x) { x } == unda(x) { x }`, false},
+		{`fanya f = unda(x) { x }; fanya g = unda(x) { x }; f != g`, true},
+	}
+
+	for _, tt := range comparisons {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPropertyAccess(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya mtu = {"jina": "Asha", "umri": 30}; mtu.umri`, 30},
+		{`fanya mtu = {"anwani": {"mji": "Arusha"}}; mtu.anwani.mji`, "Arusha"},
+		{`fanya mtu = {"salimu": unda(x) { x * 2 }}; mtu.salimu(21)`, 42},
+		{`fanya mtu = {"jina": "Asha"}; mtu.haipo`, nil},
+		{"fanya mtu = {\"jina\": \"Asha\"};\nmtu.haipo()", errorMessage("Mstari 1: Hii sio function: mtu.haipo ni TUPU")},
+		{"fanya mtu = {\"jina\": \"Asha\"};\n\nmtu.jina()", errorMessage("Mstari 2: Hii sio function: mtu.jina ni NENO")},
+		{`fanya namba = 5; namba.jina`, errorMessage("Mstari 0: Huwezi kutumia '.jina' na NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestSafeIntegerParseBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`jaribuNambari("42", 0)`, 42},
+		{`jaribuNambari("-17", 0)`, -17},
+		{`jaribuNambari("  42\n", 0)`, 42},
+		{`jaribuNambari("4 2", -1)`, -1},
+		{`jaribuNambari("arobaini", -1)`, -1},
+		{`jaribuNambari("4.2", -1)`, -1},
+		{`jaribuNambari("", "hakuna")`, "hakuna"},
+		{`jaribuNambari(tupu, 7)`, 7},
+		{`jaribuNambari("ff", 0, 16)`, 255},
+		{`jaribuNambari("12", 0, 2)`, 0},
+		{`jaribuNambari("12", 0, 40)`, errorMessage("Mstari 0: Samahani, msingi lazima uwe kati ya 2 na 36, sio 40")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`[1, 2, 3, 4, 5][1:3]`, "[2, 3]"},
+		{`[1, 2, 3, 4, 5][:2]`, "[1, 2]"},
+		{`[1, 2, 3, 4, 5][3:]`, "[4, 5]"},
+		{`[1, 2, 3, 4, 5][:]`, "[1, 2, 3, 4, 5]"},
+		{`[1, 2, 3, 4, 5][-2:]`, "[4, 5]"},
+		{`[1, 2, 3, 4, 5][:-1]`, "[1, 2, 3, 4]"},
+		{`[1, 2, 3, 4, 5][3:1]`, "[]"},
+		{`[1, 2, 3, 4, 5][-100:100]`, "[1, 2, 3, 4, 5]"},
+		{`"habari"[1:4]`, "aba"},
+		{`"jambo 🌍!"[6:]`, "🌍!"},
+		{`fanya a = [1, 2, 3]; fanya b = a[:]; b[0] = 9; a`, "[1, 2, 3]"},
+		{`[1, 2, 3]["a":]`, errorMessage("Mstari 0: Tafadhali tumia number, sio: NENO")},
+		{`5[1:2]`, errorMessage("Mstari 0: Huwezi kukata NAMBA")},
+		{`[1, 2, 3, 4, 5][::2]`, "[1, 3, 5]"},
+		{`[1, 2, 3, 4, 5][1::2]`, "[2, 4]"},
+		{`[1, 2, 3, 4, 5][::-1]`, "[5, 4, 3, 2, 1]"},
+		{`[1, 2, 3, 4, 5][::-2]`, "[5, 3, 1]"},
+		{`[0, 1, 2, 3, 4, 5, 6, 7][1:7:3]`, "[1, 4]"},
+		{`[0, 1, 2, 3, 4, 5, 6, 7][6:1:-2]`, "[6, 4, 2]"},
+		{`[0, 1, 2, 3, 4, 5, 6, 7][-2::-3]`, "[6, 3, 0]"},
+		{`[1, 2, 3][1:3:-1]`, "[]"},
+		{`[1, 2, 3][100:-100:-1]`, "[3, 2, 1]"},
+		{`[][::-1]`, "[]"},
+		{`"habari"[::-1]`, "irabah"},
+		{`"jambo 🌍!"[::-1]`, "!🌍 obmaj"},
+		{`"abcdef"[::2]`, "ace"},
+		{`[1, 2, 3][2::9223372036854775807]`, "[3]"},
+		{`[1, 2, 3][::9223372036854775807]`, "[1]"},
+		{`[1, 2, 3][0::-9223372036854775807 - 1]`, "[1]"},
+		{`[1, 2, 3][::-9223372036854775807 - 1]`, "[3]"},
+		{`"abc"[1::9223372036854775807]`, "b"},
+		{`[1, 2, 3][::0]`, errorMessage("Mstari 0: Hatua ya kipande haiwezi kuwa 0")},
+		{`[1, 2, 3][::"a"]`, errorMessage("Mstari 0: Tafadhali tumia number, sio: NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("wrong slice for %s, got=%v, want=%s", tt.input, evaluated, expected)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestSliceAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya a = [1, 2, 3, 4, 5]; a[1:3] = [9, 9]; a`, "[1, 9, 9, 4, 5]"},
+		{`fanya a = [1, 2, 3, 4, 5]; a[1:3] = [7, 8, 9, 10]; a`, "[1, 7, 8, 9, 10, 4, 5]"},
+		{`fanya a = [1, 2, 3, 4, 5]; a[1:4] = [0]; a`, "[1, 0, 5]"},
+		{`fanya a = [1, 2, 3, 4, 5]; a[1:4] = []; a`, "[1, 5]"},
+		{`fanya a = [1, 2, 3]; a[:] = [4]; a`, "[4]"},
+		{`fanya a = [1, 2, 3]; a[-1:] = [7, 8]; a`, "[1, 2, 7, 8]"},
+		{`fanya a = [1, 2, 3]; a[:0] = [0]; a`, "[0, 1, 2, 3]"},
+		{`fanya a = [1, 2, 3]; a[3:] = [4, 5]; a`, "[1, 2, 3, 4, 5]"},
+		{`fanya a = [1, 2, 3]; a[1:2] += [9]; a`, "[1, 2, 9, 3]"},
+		{`fanya a = [1, 2, 3]; a[0:1] = 5; a`, errorMessage("Mstari 0: Kipande kinaweza kubadilishwa na ORODHA tu, sio NAMBA")},
+		{`fanya a = "abc"; a[0:1] = ["x"]`, errorMessage("Mstari 0: Huwezi kubadilisha kipande cha NENO")},
+		{`fanya a = [1, 2, 3]; a[::2] = [0, 0]`, errorMessage("Mstari 0: Huwezi kubadilisha kipande chenye hatua")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated == nil || evaluated.Inspect() != expected {
+				t.Errorf("wrong array for %s, got=%v, want=%s", tt.input, evaluated, expected)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestErrorStackTraceConcurrent(t *testing.T) {
+	input := `
+fanya ndani = unda(x) { x + kweli }
+fanya kati = unda(x) { ndani(x) }
+kati(1)
+`
+	var wg sync.WaitGroup
+	traces := make([][]string, 20)
+	for i := range traces {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if errObj, ok := testEval(input).(*object.Error); ok {
+				traces[i] = errObj.Trace
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, trace := range traces {
+		if len(trace) != 2 || trace[0] != "ndani ya ndani(), iliyoitwa Mstari 2" || trace[1] != "ndani ya kati(), iliyoitwa Mstari 3" {
+			t.Errorf("program %d has the wrong trace, got=%q", i, trace)
+		}
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	input := `
+fanya ndani = unda(x) {
+	x + kweli
+}
+fanya kati = unda(x) {
+	ndani(x)
+}
+fanya nje = unda() {
+	kati(1)
+}
+nje()
+`
+	evaluated := testEval(input)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned, got=%T(%+v)", evaluated, evaluated)
+	}
+
+	expected := []string{
+		"ndani ya ndani(), iliyoitwa Mstari 5",
+		"ndani ya kati(), iliyoitwa Mstari 8",
+		"ndani ya nje(), iliyoitwa Mstari 10",
+	}
+	if len(errObj.Trace) != len(expected) {
+		t.Fatalf("wrong trace length, got=%q", errObj.Trace)
+	}
+	for i, frame := range expected {
+		if errObj.Trace[i] != frame {
+			t.Errorf("wrong frame %d, got=%q, want=%q", i, errObj.Trace[i], frame)
+		}
+	}
+
+	inspected := errObj.Inspect()
+	for _, frame := range expected {
+		if !strings.Contains(inspected, "\n\t"+frame) {
+			t.Errorf("printed error is missing %q, got=%q", frame, inspected)
+		}
+	}
+
+	topLevel := testEval("5 + kweli").(*object.Error)
+	if len(topLevel.Trace) != 0 {
+		t.Errorf("error outside a function has a trace, got=%q", topLevel.Trace)
+	}
+
+	anonymous := testEval("unda() { 5 + kweli }()").(*object.Error)
+	if len(anonymous.Trace) != 1 || anonymous.Trace[0] != "ndani ya unda(), iliyoitwa Mstari 0" {
+		t.Errorf("wrong trace for anonymous function, got=%q", anonymous.Trace)
+	}
+}
+
+func evalWithArray(input string, size int) object.Object {
+	elements := make([]object.Object, size)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	env := object.NewEnvironment()
+	env.Set("orodha", &object.Array{Elements: elements})
+
+	l := lexer.New(input)
+	p := parser.New(l)
+	return Eval(p.ParseProgram(), env)
+}
+
+func TestForInLargeArray(t *testing.T) {
+	const size = 100000
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// sum of 0..size-1
+		{`fanya s = 0; kwa v ktk orodha { s += v }; s`, size * (size - 1) / 2},
+		{`fanya s = 0; kwa i, v ktk orodha { s += i }; s`, size * (size - 1) / 2},
+		// stops at the break
+		{`fanya n = 0; kwa v ktk orodha { kama (v == 5000) { vunja }; n++ }; n`, 5000},
+		// skips the odd values
+		{`fanya n = 0; kwa v ktk orodha { kama (v % 2 == 1) { endelea }; n++ }; n`, size / 2},
+		// the array can be looped over again after a break
+		{`kwa v ktk orodha { kama (v == 10) { vunja } }; fanya n = 0; kwa v ktk orodha { n++ }; n`, size},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, evalWithArray(tt.input, size), tt.expected)
+	}
+}
+
+// The array iterator walks the existing slice, so memory use stays flat no
+// matter how big the array is. Run with -benchmem to see the allocations
+// per element.
+func BenchmarkForInLargeArray(b *testing.B) {
+	const size = 10000000
+
+	elements := make([]object.Object, size)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	arr := &object.Array{Elements: elements}
+	program := parser.New(lexer.New(`kwa v ktk orodha { endelea }`)).ParseProgram()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		env.Set("orodha", arr)
+		Eval(program, env)
+	}
+}
+
+func TestPrefixOperatorsOnStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`-"5"`, errorMessage("Mstari 0: Operesheni Haielweki: -NENO. Tumia nambari() kubadili neno kuwa namba kwanza")},
+		{`+"5"`, errorMessage("Mstari 0: Operesheni Haielweki: +NENO. Tumia nambari() kubadili neno kuwa namba kwanza")},
+		{`+kweli`, errorMessage("Mstari 0: Operesheni Haielweki: +BOOLEAN")},
+		{`-nambari("5")`, -5},
+		{`+nambari(" 42 ")`, 42},
+		{`-nambari("2.5")`, -2.5},
+		{`nambari("mbili")`, errorMessage(`Mstari 0: Samahani, "mbili" sio namba`)},
+		{`nambari([1])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestComprehensions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[x * 2 kwa x ktk [1, 2, 3]]`, "[2, 4, 6]"},
+		{`[x kwa x ktk [1, -2, 3, -4] kama x > 0]`, "[1, 3]"},
+		{`[i kwa i, x ktk ["a", "b"]]`, "[0, 1]"},
+		{`[x kwa x ktk [1, 2] kama x > 5]`, "[]"},
+		{`{v: k kwa k, v ktk {"a": 1, "b": 2}}`, "{1: a, 2: b}"},
+		{`{k: v * 10 kwa k, v ktk {"a": 1, "b": 2, "c": 3} kama v != 2}`, "{a: 10, c: 30}"},
+		{`fanya x = 5; [x kwa x ktk [1, 2]]; x`, "5"},
+		{`[k kwa k ktk {"a": 1, "b": 2}]`, "[a, b]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	testErrorObject(t, testEval(`[x kwa x ktk 5]`), "Mstari 0: Huwezi kufanya operesheni hii na NAMBA")
+}
+
+func TestDictPairBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`vipengele({"a": 1, "b": 2})`, "[[a, 1], [b, 2]]"},
+		{`vipengele({})`, "[]"},
+		{`kutokaVipengele([["a", 1], ["b", 2]])`, "{a: 1, b: 2}"},
+		{`fanya k = {"z": 1, "a": [2], 3: kweli}; kutokaVipengele(vipengele(k))`, "{z: 1, a: [2], 3: kweli}"},
+		{`vipengele([1])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+		{`kutokaVipengele([["a", 1], "b"])`, errorMessage("Mstari 0: Samahani, kipengele 1 sio jozi ya [key, thamani]: b")},
+		{`kutokaVipengele([["a", 1, 2]])`, errorMessage("Mstari 0: Samahani, kipengele 0 sio jozi ya [key, thamani]: [a, 1, 2]")},
+		{`kutokaVipengele([[[1], 2]])`, errorMessage("Mstari 0: Samahani, ORODHA haitumiki kama key")},
+		{`funguo({"z": 1, "a": 2, 3: kweli})`, "[z, a, 3]"},
+		{`thamani({"z": 1, "a": 2, 3: kweli})`, "[1, 2, kweli]"},
+		{`fanya k = {"a": 1}; k["b"] = 2; k["a"] = 3; [funguo(k), thamani(k)]`, "[[a, b], [3, 2]]"},
+		{`funguo({})`, "[]"},
+		{`thamani({})`, "[]"},
+		{`funguo([1, 2])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+		{`thamani({}, {})`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 2")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestHTTPBuiltins(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Njia", r.Method)
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, "%s|%s", r.Header.Get("Content-Type"), body)
+			return
+		}
+		fmt.Fprint(w, "habari")
+	}))
+	defer server.Close()
+
+	defaultClient := httpClient
+	httpClient = server.Client()
+	defer func() { httpClient = defaultClient }()
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{fmt.Sprintf(`pata("%s")["msimbo"]`, server.URL), 200},
+		{fmt.Sprintf(`pata("%s")["mwili"]`, server.URL), "habari"},
+		{fmt.Sprintf(`pata("%s")["vichwa"]["X-Njia"]`, server.URL), "GET"},
+		{fmt.Sprintf(`tuma("%s", "jambo", {"Content-Type": "text/plain"})["msimbo"]`, server.URL), 201},
+		{fmt.Sprintf(`tuma("%s", "jambo", {"Content-Type": "text/plain"})["mwili"]`, server.URL), "text/plain|jambo"},
+		{fmt.Sprintf(`tuma("%s", "jambo")["vichwa"]["X-Njia"]`, server.URL), "POST"},
+		{`pata(1)`, errorMessage("Mstari 0: Samahani, url lazima iwe NENO, sio NAMBA")},
+		{`tuma("http://mfano.com", "", [])`, errorMessage("Mstari 0: Samahani, vichwa lazima viwe KAMUSI, sio ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	// a closed server gives a network error, not a crash
+	server.Close()
+	evaluated := testEval(fmt.Sprintf(`pata("%s")`, server.URL))
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("expected an error after the server closed, got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
+func TestEncodingBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`base64Simba("habari")`, "aGFiYXJp"},
+		{`base64Fungua("aGFiYXJp")`, "habari"},
+		{`base64Simba("")`, ""},
+		{`base64Fungua(base64Simba("\0ÿ\n"))`, "\x00ÿ\n"},
+		{`base64Fungua(base64Simba("jambo 🌍"))`, "jambo 🌍"},
+		{`base64Fungua("aGFiYXJp!")`, errorMessage("Mstari 0: Samahani, neno hili haliwezi kufunguliwa: illegal base64 data at input byte 8")},
+		{`base64Simba(5)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+		{`urlSimba("a b&c=d/é")`, "a+b%26c%3Dd%2F%C3%A9"},
+		{`urlFungua("a+b%26c%3Dd%2F%C3%A9")`, "a b&c=d/é"},
+		{`urlFungua(urlSimba("\0?#%"))`, "\x00?#%"},
+		{`urlFungua("%zz")`, errorMessage(`Mstari 0: Samahani, neno hili haliwezi kufunguliwa: invalid URL escape "%zz"`)},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`sha256("")`, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{`sha256("abc")`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{`md5("")`, "d41d8cd98f00b204e9800998ecf8427e"},
+		{`md5("abc")`, "900150983cd24fb0d6963f7d28e17f72"},
+		{`md5("é") == md5("é")`, true},
+		{`sha256(1)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+		{`md5()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestCsvBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`somaCsv("jina,umri\nAsha,30\n")`, "[[jina, umri], [Asha, 30]]"},
+		{`somaCsv("\"Dar es Salaam, TZ\",1")[0][0]`, "Dar es Salaam, TZ"},
+		{`somaCsv("\"mstari\nmwingine\",2")[0][0]`, "mstari\nmwingine"},
+		{`somaCsv("\"alisema \"\"jambo\"\"\"")[0][0]`, `alisema "jambo"`},
+		{`somaCsv("")`, "[]"},
+		{`andikaCsv([["a", "b, c"], [1, 2.5]])`, "a,\"b, c\"\n1,2.5\n"},
+		{`andikaCsv(somaCsv("x,\"y\nz\"\n\"q\"\"\",r\n"))`, "x,\"y\nz\"\n\"q\"\"\",r\n"},
+		{`somaCsv("a,\"b")`, errorMessage(`Mstari 0: Samahani, CSV si sahihi: parse error on line 1, column 5: extraneous or missing " in quoted-field`)},
+		{`somaCsv("a,b\nc")`, errorMessage("Mstari 0: Samahani, CSV si sahihi: record on line 2: wrong number of fields")},
+		{`andikaCsv([["a"], "b"])`, errorMessage("Mstari 0: Samahani, safu 1 lazima iwe ORODHA, sio NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if arr, ok := evaluated.(*object.Array); ok {
+				if arr.Inspect() != expected {
+					t.Errorf("%s: expected=%q, got=%q", tt.input, expected, arr.Inspect())
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestFileSystemBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.txt", "a.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "hakuna")
+	created := filepath.Join(dir, "mpya", "ndani")
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{fmt.Sprintf("orodhaDir(%q)", dir), "[a.txt, b.txt]"},
+		{fmt.Sprintf("nipo(%q)", filepath.Join(dir, "a.txt")), true},
+		{fmt.Sprintf("nipo(%q)", missing), false},
+		{`unganishaNjia("a", "b", "c.txt")`, filepath.Join("a", "b", "c.txt")},
+		{`unganishaNjia("a/", "../b")`, "b"},
+		{fmt.Sprintf("fanyaDir(%q); nipo(%q)", created, created), true},
+		{fmt.Sprintf("fanyaDir(%q); orodhaDir(%q)", created, filepath.Join(dir, "mpya")), "[ndani]"},
+		{`unganishaNjia("a", 1)`, errorMessage("Mstari 0: Samahani, njia lazima iwe NENO, sio NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if arr, ok := evaluated.(*object.Array); ok {
+				if arr.Inspect() != expected {
+					t.Errorf("%s: expected=%q, got=%q", tt.input, expected, arr.Inspect())
+				}
+				continue
+			}
+			testStringObject(t, evaluated, expected)
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	// the OS decides the rest of the message, so only check the prefix
+	failures := map[string]string{
+		fmt.Sprintf("orodhaDir(%q)", missing):                         "Mstari 0: Samahani, imeshindikana kusoma folda: ",
+		fmt.Sprintf("fanyaDir(%q)", filepath.Join(dir, "a.txt", "b")): "Mstari 0: Samahani, imeshindikana kutengeneza folda: ",
+	}
+	for input, prefix := range failures {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok {
+			t.Errorf("%s: expected an error", input)
+			continue
+		}
+		if !strings.HasPrefix(errObj.Message, prefix) {
+			t.Errorf("%s: wrong error message, got=%q", input, errObj.Message)
+		}
+	}
+}
+
+func TestReadLinesBuiltin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kumbukumbu.txt")
+	if err := os.WriteFile(path, []byte("moja\nmbili\ntatu\nnne\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{fmt.Sprintf(`fanya m = []; kwa l ktk somaMistari(%q) { m = sukuma(m, l) }; m`, path), "[moja, mbili, tatu, nne]"},
+		{fmt.Sprintf(`fanya m = []; kwa i, l ktk somaMistari(%q) { kama (i == 2) { vunja }; m = sukuma(m, l) }; m`, path), "[moja, mbili]"},
+		{fmt.Sprintf(`[l kwa l ktk somaMistari(%q) kama l != "mbili"]`, path), "[moja, tatu, nne]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	evaluated := testEval(fmt.Sprintf(`somaMistari(%q)`, filepath.Join(t.TempDir(), "hakuna.txt")))
+	if _, ok := evaluated.(*object.Error); !ok {
+		t.Errorf("expected an error for a missing file, got=%T", evaluated)
+	}
+}
+
+func TestSwitchGuardsAndRanges(t *testing.T) {
+	grade := `
+	fanya daraja = unda(alama) {
+		badili (alama) {
+			ikiwa 100 { rudisha "kamili" }
+			ikiwa >= 80 { rudisha "A" }
+			ikiwa >= 60 { rudisha "B" }
+			ikiwa kama alama < 0 || alama > 100 { rudisha "batili" }
+			kawaida { rudisha "C" }
+		}
+	};
+	`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{grade + `daraja(100)`, "kamili"},
+		{grade + `daraja(85)`, "A"},
+		{grade + `daraja(80)`, "A"},
+		{grade + `daraja(79)`, "B"},
+		{grade + `daraja(-5)`, "batili"},
+		{grade + `daraja(150)`, "A"},
+		{grade + `daraja(10)`, "C"},
+		{`fanya x = 3; badili (x) { ikiwa != 3 { "hapana" } ikiwa 3 { "ndio" } }`, "ndio"},
+		{`fanya x = 5; badili (x) { ikiwa kama x % 2 == 0 { "shufwa" } kawaida { "witiri" } }`, "witiri"},
+		{`badili ("a") { ikiwa < 5 { 1 } }`, errorMessage("Mstari 0: Aina Hazilingani: NENO < NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestNumberFormatBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`umboNamba(0)`, "0"},
+		{`umboNamba(999)`, "999"},
+		{`umboNamba(1000)`, "1,000"},
+		{`umboNamba(1234567)`, "1,234,567"},
+		{`umboNamba(-1234567)`, "-1,234,567"},
+		{`umboNamba(-999)`, "-999"},
+		{`umboNamba(9223372036854775807)`, "9,223,372,036,854,775,807"},
+		{`umboNamba(1234567, " ")`, "1 234 567"},
+		{`umboNamba(1234.5)`, "1,234.50"},
+		{`umboNamba(-1234.5678, ",", 3)`, "-1,234.568"},
+		{`umboNamba(1234567.891, ".", 0)`, "1.234.568"},
+		{`umboNamba(0.5, ",", 1)`, "0.5"},
+		{`umboNamba("1000")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`umboNamba(1, 2)`, errorMessage("Mstari 0: Samahani, kitenganishi lazima kiwe NENO, sio NAMBA")},
+		{`umboNamba(1.5, ",", -1)`, errorMessage("Mstari 0: Samahani, usahihi lazima uwe NAMBA isiyo hasi, sio -1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestTimingBuiltin(t *testing.T) {
+	l := lexer.New(`pimaMuda(unda() { lala(); rudisha "tayari" })`)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+	env.Set("lala", &object.Builtin{Fn: func(line int, args ...object.Object) object.Object {
+		time.Sleep(20 * time.Millisecond)
+		return NULL
+	}})
+
+	result, ok := Eval(program, env).(*object.Array)
+	if !ok || len(result.Elements) != 2 {
+		t.Fatalf("expected a [matokeo, muda] pair, got=%+v", result)
+	}
+	testStringObject(t, result.Elements[0], "tayari")
+	millis, ok := result.Elements[1].(*object.Float)
+	if !ok {
+		t.Fatalf("expected the duration to be a DESIMALI, got=%T", result.Elements[1])
+	}
+	if millis.Value < 20 || millis.Value > 5000 {
+		t.Errorf("implausible duration %fms for a 20ms sleep", millis.Value)
+	}
+
+	testErrorObject(t, testEval(`pimaMuda(5)`), "Mstari 0: Hii sio function: NAMBA")
+	testErrorObject(t, testEval(`pimaMuda(unda() { 1 + "a" })`), "Mstari 0: Aina Hazilingani: NAMBA + NENO")
+}
+
+func TestFindSubstringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`tafuta("habari yako", "yako")`, 7},
+		{`tafuta("habari", "a")`, 1},
+		{`tafutaMwisho("habari", "a")`, 3},
+		{`tafuta("habari", "z")`, -1},
+		{`tafutaMwisho("habari", "z")`, -1},
+		{`tafuta("aaaa", "aa")`, 0},
+		{`tafutaMwisho("aaaa", "aa")`, 2},
+		{`tafuta("café au lait", "au")`, 5},
+		{`tafutaMwisho("🌍 na 🌍", "🌍")`, 5},
+		{`fanya s = "ndizi, embe"; s[tafuta(s, ",") + 2:]`, "embe"},
+		{`tafuta("abc", "")`, 0},
+		{`tafuta(1, "a")`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe NENO, sio NAMBA")},
+		{`tafutaMwisho("a", [])`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe NENO, sio ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestNestedIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya m = [[1, 2, 3], [4, 5, 6]]; m[1][2] = 9; m`, "[[1, 2, 3], [4, 5, 9]]"},
+		{`fanya m = [[1, 2], [3, 4]]; m[0][1] += 10; m`, "[[1, 12], [3, 4]]"},
+		{`fanya m = [[[0]]]; m[0][0][0] = 1; m`, "[[[1]]]"},
+		{`fanya d = {"a": {"b": 1}}; d["a"]["b"] = 2; d["a"]["c"] = 3; d`, "{a: {b: 2, c: 3}}"},
+		{`fanya d = {"a": [1, 2]}; d["a"][0] = "x"; d`, "{a: [x, 2]}"},
+		{`fanya a = [{"k": 1}]; a[0]["k"] = 5; a`, "[{k: 5}]"},
+		{`fanya a = [1, 2, 3]; a[3] = 4`, errorMessage("Mstari 0: Index imezidi idadi ya elements")},
+		{`fanya a = [1, 2, 3]; a[-1] = 4`, errorMessage("Mstari 0: Index imezidi idadi ya elements")},
+		{`fanya m = [[1]]; m[0]["a"] = 4`, errorMessage("Mstari 0: Tafadhali tumia number, sio: NENO")},
+		{`fanya d = {"a": 1}; d["a"]["b"] = 2`, errorMessage("Mstari 0: Operesheni hii haiwezekani kwa: NAMBA")},
+		{`fanya d = {"a": {}}; d["a"][[1]] = 2`, errorMessage("Mstari 0: Samahani, ORODHA haitumiki kama key")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestBaseEnvironmentConstants(t *testing.T) {
+	eval := func(input string) object.Object {
+		program := parser.New(lexer.New(input)).ParseProgram()
+		return Eval(program, NewEnvironment())
+	}
+
+	if eval("TUPU") != NULL {
+		t.Errorf("TUPU is not NULL")
+	}
+	testBooleanObject(t, eval("TUPU == tupu"), true)
+	testIntegerObject(t, eval("NAMBA_KUBWA"), 9223372036854775807)
+	testIntegerObject(t, eval("NAMBA_NDOGO"), -9223372036854775808)
+	testBooleanObject(t, eval("NAMBA_NDOGO < 0 && NAMBA_KUBWA > 0"), true)
+}
+
+func TestGetOrDefaultBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`pataAu({"a": 1}, "a", 0)`, 1},
+		{`pataAu({"a": 1}, "b", 0)`, 0},
+		{`pataAu({"a": tupu}, "a", 5)`, nil},
+		{`pataAu({}, 1, "hakuna")`, "hakuna"},
+		{`fanya d = {"a": 1}; pataAu(d, "b", 2); d`, "{a: 1}"},
+		{`pataAu([1], 0, 0)`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe KAMUSI, sio ORODHA")},
+		{`pataAu({}, [1], 0)`, errorMessage("Mstari 0: Samahani, ORODHA haitumiki kama key")},
+		{`pataAu({}, "a")`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 3, wewe umeweka 2")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestLogicalAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya x = tupu; x ||= 5; x`, 5},
+		{`fanya x = sikweli; x ||= "chaguo"; x`, "chaguo"},
+		{`fanya x = 3; x ||= 5; x`, 3},
+		{`fanya x = kweli; x &&= 7; x`, 7},
+		{`fanya x = tupu; x &&= 7; x`, nil},
+		{`fanya x = sikweli; x &&= kweli; x`, false},
+		// the right hand side is skipped entirely when it is not needed
+		{`fanya n = 0; fanya f = unda() { n += 1; rudisha 9 }; fanya x = 1; x ||= f(); n`, 0},
+		{`fanya n = 0; fanya f = unda() { n += 1; rudisha 9 }; fanya x = tupu; x &&= f(); n`, 0},
+		{`fanya x = 1; x ||= 1 + "a"; x`, 1},
+		{`fanya x = tupu; x ||= 1 + "a"`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + NENO")},
+		{`fanya d = {}; d["a"] ||= [1]; d["a"] ||= [2]; d`, "{a: [1]}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"vunja", errorMessage("Mstari 0: 'vunja' nje ya kitanzi")},
+		{"endelea", errorMessage("Mstari 0: 'endelea' nje ya kitanzi")},
+		{"fanya x = 1\nkama (x == 1) {\n\tvunja\n}\nx = 2", errorMessage("Mstari 2: 'vunja' nje ya kitanzi")},
+		{"fanya f = unda() { endelea }\nf()", errorMessage("Mstari 0: 'endelea' nje ya kitanzi")},
+		// a function cannot break the loop it was called from
+		{"fanya f = unda() { vunja }; fanya n = 0; kwa i ktk [1, 2] { n += 1; f() }", errorMessage("Mstari 0: 'vunja' nje ya kitanzi")},
+		{"fanya n = 0; kwa i ktk [1, 2, 3] { kama (i == 2) { vunja }; n += i }; n", 1},
+		{"fanya n = 0; kwa i ktk [1, 2, 3] { kama (i == 2) { endelea }; n += i }; n", 4},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestRepetitionWithZeroAndNegativeCounts(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"ab" * 3`, "ababab"},
+		{`"ab" * 0`, ""},
+		{`"ab" * -1`, ""},
+		{`0 * "ab"`, ""},
+		{`-5 * "ab"`, ""},
+		{`[1, 2] * 0`, "[]"},
+		{`[1, 2] * -1`, "[]"},
+		{`0 * [1, 2]`, "[]"},
+		{`-3 * [1, 2]`, "[]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestRepetitionTooLong(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"ab" * 4611686018427387904`, "Mstari 0: Samahani, neno hili ni refu mno, kikomo ni baiti 100000000"},
+		{`9223372036854775807 * "a"`, "Mstari 0: Samahani, neno hili ni refu mno, kikomo ni baiti 100000000"},
+		{`"ab" * 50000001`, "Mstari 0: Samahani, neno hili ni refu mno, kikomo ni baiti 100000000"},
+		{`[1, 2] * 4611686018427387904`, "Mstari 0: Samahani, orodha hii ni ndefu mno, kikomo ni vitu 10000000"},
+		{`9223372036854775807 * [1]`, "Mstari 0: Samahani, orodha hii ni ndefu mno, kikomo ni vitu 10000000"},
+		{`[1, 2] * 5000001`, "Mstari 0: Samahani, orodha hii ni ndefu mno, kikomo ni vitu 10000000"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	evaluated := testEval(`"" * 9223372036854775807`)
+	if evaluated.Inspect() != "" {
+		t.Errorf("expected an empty string, got=%q", evaluated.Inspect())
+	}
+	evaluated = testEval(`[] * 9223372036854775807`)
+	if evaluated.Inspect() != "[]" {
+		t.Errorf("expected an empty array, got=%q", evaluated.Inspect())
+	}
+}
+
+func TestArrayRepetitionDoesNotAlias(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fanya a = [1, 2]; fanya b = a * 1; b[0] = 9; [a, b]`, "[[1, 2], [9, 2]]"},
+		{`fanya a = [1, 2]; fanya b = 1 * a; b[0] = 9; [a, b]`, "[[1, 2], [9, 2]]"},
+		{`fanya a = [1, 2]; fanya b = a * 3; b[0] = 9; [a, b]`, "[[1, 2], [9, 2, 1, 2, 1, 2]]"},
+		{`fanya a = [1, 2]; fanya b = a * 0; b = sukuma(b, 5); [a, b]`, "[[1, 2], [5]]"},
+		{`fanya a = [1, 2]; fanya b = a * -2; [a, b]`, "[[1, 2], []]"},
+		{`fanya a = [1, 2]; fanya b = a * 2; a[1] = 7; b`, "[1, 2, 1, 2]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	salimu := `fanya salimu = unda(salamu, jina, umri) { salamu + " " + jina + " " + aina(umri) }; `
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{salimu + `salimu(umri: 20, jina: "Asha", salamu: "Habari")`, "Habari Asha NAMBA"},
+		{salimu + `salimu("Mambo", umri: 1.5, jina: "Juma")`, "Mambo Juma DESIMALI"},
+		{salimu + `salimu("Mambo", "Juma", umri: 3)`, "Mambo Juma NAMBA"},
+		{`fanya f = unda(a, b) { [a, b] }; f(b: 2, a: 1)`, "[1, 2]"},
+		{`fanya f = unda(a, b) { a }; f(a: 1)`, "1"},
+		{salimu + `salimu("Mambo", rangi: "nyekundu")`, errorMessage("Mstari 0: salimu() haina hoja inayoitwa 'rangi'")},
+		{salimu + `salimu("Mambo", jina: "a", jina: "b")`, errorMessage("Mstari 0: Hoja 'jina' imepewa thamani zaidi ya mara moja")},
+		{salimu + `salimu("Mambo", salamu: "Habari")`, errorMessage("Mstari 0: Hoja 'salamu' imepewa thamani zaidi ya mara moja")},
+		{`andika(ujumbe: "habari")`, errorMessage("Mstari 0: YA_NDANI haikubali hoja zenye majina")},
+		{`fanya f = unda(a) { a }; f(a: 1 + "b")`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestRotateAndFrontBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`zungusha_orodha([1, 2, 3, 4], 1)`, "[2, 3, 4, 1]"},
+		{`zungusha_orodha([1, 2, 3, 4], 5)`, "[2, 3, 4, 1]"},
+		{`zungusha_orodha([1, 2, 3, 4], -1)`, "[4, 1, 2, 3]"},
+		{`zungusha_orodha([1, 2, 3, 4], -6)`, "[3, 4, 1, 2]"},
+		{`zungusha_orodha([1, 2, 3], 0)`, "[1, 2, 3]"},
+		{`zungusha_orodha([], 3)`, "[]"},
+		{`fanya a = [1, 2, 3]; fanya b = zungusha_orodha(a, 0); b[0] = 9; a`, "[1, 2, 3]"},
+		{`sukumaMbele([2, 3], 1)`, "[1, 2, 3]"},
+		{`sukumaMbele([], "a")`, "[a]"},
+		{`toaMbele([1, 2, 3])`, "[2, 3]"},
+		{`toaMbele([])`, "[]"},
+		{`fanya a = [1, 2]; sukumaMbele(a, 0); toaMbele(a); a`, "[1, 2]"},
+		{`zungusha_orodha([1], "a")`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe NAMBA, sio NENO")},
+		{`toaMbele("abc")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestApproxEqualBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`0.1 + 0.2 == 0.3`, false},
+		{`karibuSawa(0.1 + 0.2, 0.3)`, true},
+		{`karibuSawa(1, 1.0)`, true},
+		{`karibuSawa(1.0, 1.001)`, false},
+		{`karibuSawa(1.0, 1.001, 0.01)`, true},
+		{`karibuSawa(10, 12, 2)`, true},
+		{`karibuSawa(10, 12.5, 2)`, false},
+		{`karibuSawa(-1.5, 1.5, 1)`, false},
+		{`karibuSawa("a", 1)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`karibuSawa(1, 1, -1)`, errorMessage("Mstari 0: Samahani, uvumilivu lazima uwe namba isiyo hasi, sio -1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestApplyBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya jumlisha = unda(a, b) { a + b }; tumiaFn(jumlisha, [2, 3])`, 5},
+		{`tumiaFn(unda() { 7 }, [])`, 7},
+		{`tumiaFn(idadi, ["habari"])`, 6},
+		{`fanya jumlisha = unda(a, b) { a + b }; tumiaFn(jumlisha, [2])`, errorMessage("Mstari 0: Neno Halifahamiki: b")},
+		{`tumiaFn(idadi, ["a", "b"])`, errorMessage("Mstari 0: Hoja hazilingani, tunahitaji=1, tumepewa=2")},
+		{`tumiaFn(5, [])`, errorMessage("Mstari 0: Hii sio function: NAMBA")},
+		{`tumiaFn(idadi, "a")`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe ORODHA, sio NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestIntegerFloatComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 == 1.0", true},
+		{"1.0 == 1", true},
+		{"1 != 1.0", false},
+		{"1.0 != 1", false},
+		{"1 == 1.5", false},
+		{"1 != 1.5", true},
+		{"-0.0 == 0", true},
+		{"1 < 1.5", true},
+		{"1.5 < 1", false},
+		{"2 <= 2.0", true},
+		{"2.0 >= 2", true},
+		{"3 > 2.9", true},
+		{"2.9 > 3", false},
+		{"[1 == 1.0, 1.0 == 1][0]", true},
+		{"fanya a = 2; fanya b = 4.0 / 2; a == b", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestSandboxEnvironment(t *testing.T) {
+	env := NewSandboxEnv([]string{"idadi", "bana", "jumla"})
+	eval := func(input string) object.Object {
+		return Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	}
+
+	testIntegerObject(t, eval(`bana(15, 0, 10)`), 10)
+	testIntegerObject(t, eval(`jumla([1, 2, 3])`), 6)
+	testIntegerObject(t, eval(`fanya f = unda(s) { idadi(s) }; f("abc")`), 3)
+	testBooleanObject(t, eval(`NAMBA_KUBWA > 0`), true)
+
+	testErrorObject(t, eval(`orodhaDir(".")`), "Mstari 0: Neno Halifahamiki: orodhaDir")
+	testErrorObject(t, eval(`pata("http://mfano.com")`), "Mstari 0: Neno Halifahamiki: pata")
+	// closures defined in the sandbox stay in the sandbox
+	testErrorObject(t, eval(`fanya g = unda() { fanyaDir("x") }; g()`), "Mstari 0: Neno Halifahamiki: fanyaDir")
+
+	// a normal environment still sees every builtin
+	if _, ok := testEval(`orodhaDir`).(*object.Builtin); !ok {
+		t.Errorf("orodhaDir missing outside the sandbox")
+	}
+}
+
+func TestOutputCanBeRedirected(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stdout)
+
+	testEval(`andika("habari", 5, [1, 2]); andika(); andika("tena")`)
+
+	expected := "habari 5 [1, 2]\n\ntena\n"
+	if buf.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, buf.String())
+	}
+}
+
+func TestBitBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`hesabuBiti(0)`, 0},
+		{`hesabuBiti(1)`, 1},
+		{`hesabuBiti(255)`, 8},
+		{`hesabuBiti(kutokaMsingi("101101", 2))`, 4},
+		{`hesabuBiti(-1)`, 64},
+		{`hesabuBiti(-2)`, 63},
+		{`bitiYaJuu(0)`, -1},
+		{`bitiYaJuu(1)`, 0},
+		{`bitiYaJuu(8)`, 3},
+		{`bitiYaJuu(255)`, 7},
+		{`bitiYaJuu(256)`, 8},
+		{`bitiYaJuu(-1)`, 63},
+		{`badiliBaiti(0)`, 0},
+		{`badiliBaiti(1)`, 72057594037927936},
+		{`badiliBaiti(kutokaMsingi("0102030405060708", 16))`, 0x0807060504030201},
+		{`badiliBaiti(badiliBaiti(123456789))`, 123456789},
+		{`badiliBaiti(-1)`, -1},
+		{`badiliBaiti(128)`, -9223372036854775808},
+		{`hesabuBiti(1.5)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na DESIMALI")},
+		{`bitiYaJuu()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestForInSingleVariable(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fanya m = []; kwa k ktk {"b": 1, "a": 2, 3: 3} { m = sukuma(m, k) }; m`, "[b, a, 3]"},
+		{`fanya m = []; kwa k, v ktk {"b": 1, "a": 2} { m = sukuma(m, [k, v]) }; m`, "[[b, 1], [a, 2]]"},
+		{`fanya k = {"a": 1, "b": 2}; fanya s = 0; kwa x ktk k { s += k[x] }; s`, "3"},
+		{`fanya m = []; kwa k ktk {} { m = sukuma(m, k) }; m`, "[]"},
+		{`fanya m = []; kwa v ktk [4, 5] { m = sukuma(m, v) }; m`, "[4, 5]"},
+		{`fanya m = []; kwa v ktk "ab" { m = sukuma(m, v) }; m`, "[a, b]"},
+		{`fanya m = []; kwa v ktk "café ✓" { m = sukuma(m, v) }; m`, "[c, a, f, é,  , ✓]"},
+		{`fanya m = []; kwa i, v ktk "aé✓" { m = sukuma(m, [i, v]) }; m`, "[[0, a], [1, é], [2, ✓]]"},
+		{`fanya n = 0; kwa _, c ktk "habari" { n += 1 }; n`, "6"},
+		{`[c kwa c ktk "jé"]`, "[j, é]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestCallNonFunctionNamesCallee(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fanya x = 5\nfanya y = x + 1\ny(2)", "Mstari 2: Hii sio function: y ni NAMBA"},
+		{`fanya orodha = [1, 2]; orodha[0]()`, "Mstari 0: Hii sio function: orodha[0] ni NAMBA"},
+		{`haipo()`, "Mstari 0: Neno Halifahamiki: haipo"},
+		{`"neno"()`, "Mstari 0: Hii sio function: neno ni NENO"},
+		{`fanya f = unda() { rudisha 1 }; f()()`, "Mstari 0: Hii sio function: f() ni NAMBA"},
+	}
+
+	for _, tt := range tests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJoinDigitsBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`sombaNambari(["4", "2"])`, 42},
+		{`sombaNambari(["0", "0", "7"])`, 7},
+		{`sombaNambari(["-", "1", "5"])`, -15},
+		{`sombaNambari(["3", ".", "2", "5"])`, 3.25},
+		{`sombaNambari([52, 50])`, 42},
+		{`sombaNambari(["1", 50, "3"])`, 123},
+		{`sombaNambari([])`, errorMessage(`Mstari 0: Samahani, "" sio namba`)},
+		{`sombaNambari(["1", "-"])`, errorMessage(`Mstari 0: Samahani, "1-" sio namba`)},
+		{`sombaNambari(["1", ".", "2", ".", "3"])`, errorMessage(`Mstari 0: Samahani, "1.2.3" sio namba`)},
+		{`sombaNambari(["1", "a"])`, errorMessage(`Mstari 0: Samahani, kipengele 1 sio sehemu ya namba: "a"`)},
+		{`sombaNambari(["12", "3"])`, errorMessage(`Mstari 0: Samahani, kipengele 0 lazima kiwe herufi moja, sio "12"`)},
+		{`sombaNambari([1])`, errorMessage(`Mstari 0: Samahani, kipengele 0 sio sehemu ya namba: "\x01"`)},
+		{`sombaNambari([-1])`, errorMessage("Mstari 0: Samahani, kipengele 0 sio herufi sahihi: -1")},
+		{`sombaNambari([kweli])`, errorMessage("Mstari 0: Samahani, kipengele 0 lazima kiwe NENO au NAMBA, sio BOOLEAN")},
+		{`sombaNambari("42")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestUtf8Builtins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`niUtf8Sahihi("habari")`, true},
+		{`niUtf8Sahihi("")`, true},
+		{`niUtf8Sahihi("café ✓")`, true},
+		{`niUtf8Sahihi(mbovu)`, false},
+		{`niUtf8Sahihi(nusu)`, false},
+		{`safishaUtf8("café ✓")`, "café ✓"},
+		{`safishaUtf8(mbovu)`, "ha�ri�"},
+		{`safishaUtf8(nusu)`, "caf�"},
+		{`niUtf8Sahihi(safishaUtf8(mbovu))`, true},
+		{`niUtf8Sahihi(5)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+		{`safishaUtf8()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		// strings from a Nuru literal are always valid, so the broken ones are made here
+		env.Set("mbovu", &object.String{Value: "ha\xffri\xc0\xaf"})
+		env.Set("nusu", &object.String{Value: "caf\xc3"})
+		evaluated := Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestFloorDivision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 ~/ 2", 3},
+		{"-7 ~/ 2", -4},
+		{"7 ~/ -2", -4},
+		{"-7 ~/ -2", 3},
+		{"6 ~/ 2", 3},
+		{"-6 ~/ 2", -3},
+		{"0 ~/ 5", 0},
+		{"7.5 ~/ 2", 3},
+		{"-7.5 ~/ 2", -4},
+		{"7 ~/ 2.5", 2},
+		{"1.5 ~/ 0.5", 3},
+		{"1 + 7 ~/ 2 * 2", 7},
+		{"fanya x = 9; x ~/ 4", 2},
+		{"7 / 2", 3.5},
+		{"7 ~/ 0", errorMessage("Mstari 0: Huwezi kugawanya kwa sifuri")},
+		{"7.0 ~/ 0", errorMessage("Mstari 0: Huwezi kugawanya kwa sifuri")},
+		{"7 % 0", errorMessage("Mstari 0: Huwezi kugawanya kwa sifuri")},
+		{"100000000000000000000.0 ~/ 2", errorMessage("Mstari 0: Samahani, jibu la 100000000000000000000 ~/ 2 ni kubwa mno kuwa NAMBA")},
+		{"-100000000000000000000.0 ~/ 0.5", errorMessage("Mstari 0: Samahani, jibu la -100000000000000000000 ~/ 0.5 ni kubwa mno kuwa NAMBA")},
+		{"(0.0 / 0.0) ~/ 1", errorMessage("Mstari 0: Samahani, jibu la NaN ~/ 1 ni kubwa mno kuwa NAMBA")},
+		{"(-9223372036854775807 - 1) ~/ -1", errorMessage("Mstari 0: Samahani, jibu la -9223372036854775808 ~/ -1 ni kubwa mno kuwa NAMBA")},
+		{"9000000000000000000.0 ~/ 1", 9000000000000000000},
+		{"fanya x = 7; x %= 0", errorMessage("Mstari 0: Huwezi kugawanya kwa sifuri")},
+		{"-7 % 2", -1},
+		{`"a" ~/ 2`, errorMessage("Mstari 0: Aina Hazilingani: NENO ~/ NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestShuffleBuiltin(t *testing.T) {
+	input := `
+	fanya orodha = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+	pandaMbegu(42)
+	fanya a = changanya(orodha)
+	pandaMbegu(42)
+	fanya b = changanya(orodha)
+	fanya matokeo = [orodha, a, b]
+	matokeo
+	`
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("expected an array, got=%T", evaluated)
+	}
+	original, first, second := result.Elements[0], result.Elements[1], result.Elements[2]
+
+	if original.Inspect() != "[1, 2, 3, 4, 5, 6, 7, 8, 9, 10]" {
+		t.Errorf("changanya changed its input: %s", original.Inspect())
+	}
+	if first.Inspect() != second.Inspect() {
+		t.Errorf("same seed gave different orders: %s and %s", first.Inspect(), second.Inspect())
+	}
+	if first.Inspect() == original.Inspect() {
+		t.Errorf("expected the order to change, got=%s", first.Inspect())
+	}
+
+	seen := map[int64]bool{}
+	for _, elem := range first.(*object.Array).Elements {
+		seen[elem.(*object.Integer).Value] = true
+	}
+	if len(seen) != 10 || len(first.(*object.Array).Elements) != 10 {
+		t.Errorf("shuffled array does not hold the same elements: %s", first.Inspect())
+	}
+
+	testErrorObject(t, testEval(`changanya("abc")`), "Mstari 0: Samahani, hii function haitumiki na NENO")
+	testErrorObject(t, testEval(`pandaMbegu(1.5)`), "Mstari 0: Samahani, mbegu lazima iwe NAMBA, sio DESIMALI")
+	if testEval(`changanya([])`).Inspect() != "[]" {
+		t.Errorf("expected an empty array")
+	}
+}
+
+func TestNotInOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"z" si ktk "habari"`, true},
+		{`"bar" si ktk "habari"`, false},
+		{`"bar" ktk "habari"`, true},
+		{`4 si ktk [1, 2, 3]`, true},
+		{`2 si ktk [1, 2, 3]`, false},
+		{`"a" si ktk ["a", "b"]`, false},
+		{`"c" si ktk {"a": 1, "b": 2}`, true},
+		{`"a" si ktk {"a": 1, "b": 2}`, false},
+		{`fanya si = [5]; 5 si ktk si`, false},
+		{`fanya si = 3; si * 2 == 6`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBuilders(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya m = mjengo(); kwa i ktk [1, 2, 3] { m.ongeza("x", i) }; m.matokeo()`, "x1x2x3"},
+		{`fanya m = mjengo(); m.matokeo()`, ""},
+		{`mjengo().ongeza("a").ongeza(kweli, 1.5, [1]).matokeo()`, "akweli1.5[1]"},
+		{`fanya m = mjengo(); m.ongeza("a"); fanya s = m.matokeo(); m.ongeza("b"); s + m.matokeo()`, "aab"},
+		{`fanya o = mjengoOrodha(); kwa i ktk [1, 2, 3] { o.ongeza(i * i) }; o.matokeo()`, "[1, 4, 9]"},
+		{`fanya o = mjengoOrodha(); o.ongeza([1], "a"); fanya a = o.matokeo(); o.ongeza(2); [a, o.matokeo()]`, "[[[1], a], [[1], a, 2]]"},
+		{`mjengoOrodha().matokeo()`, "[]"},
+		{`aina(mjengo())`, "MJENGO"},
+		{`mjengo().haipo()`, errorMessage("Mstari 0: Mjengo hauna '.haipo', tumia .ongeza() au .matokeo()")},
+		{`mjengo().matokeo(1)`, errorMessage("Mstari 0: Samahani, matokeo haihitaji hoja, wewe umeweka 1")},
+		{`mjengo("a")`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 0, wewe umeweka 1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+// Adding to a string copies the whole string every time, so building it in
+// a loop is quadratic while mjengo only appends.
+func BenchmarkStringConcatenation(b *testing.B) {
+	program := parser.New(lexer.New(`fanya s = ""; kwa i ktk orodha { s = s + "x" }; s`)).ParseProgram()
+	benchmarkBuilding(b, program)
+}
+
+func BenchmarkStringBuilder(b *testing.B) {
+	program := parser.New(lexer.New(`fanya m = mjengo(); kwa i ktk orodha { m.ongeza("x") }; m.matokeo()`)).ParseProgram()
+	benchmarkBuilding(b, program)
+}
+
+func benchmarkBuilding(b *testing.B, program *ast.Program) {
+	const size = 20000
+
+	elements := make([]object.Object, size)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	arr := &object.Array{Elements: elements}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		env.Set("orodha", arr)
+		if result := Eval(program, env); len(result.Inspect()) != size {
+			b.Fatalf("built the wrong string: %d characters", len(result.Inspect()))
+		}
+	}
+}
+
+func TestUUIDBuiltin(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first := testEval(`kitambulisho()`)
+	second := testEval(`kitambulisho()`)
+	for _, id := range []object.Object{first, second} {
+		str, ok := id.(*object.String)
+		if !ok {
+			t.Fatalf("expected a string, got=%T(%+v)", id, id)
+		}
+		if !uuid.MatchString(str.Value) {
+			t.Errorf("%q is not a version 4 UUID", str.Value)
+		}
+	}
+	if first.Inspect() == second.Inspect() {
+		t.Errorf("two calls gave the same id: %s", first.Inspect())
+	}
+
+	testErrorObject(t, testEval(`kitambulisho(4)`), "Mstari 0: Samahani, tunahitaji Hoja 0, wewe umeweka 1")
+}
+
+func TestScanBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`changanua(unda(a, b) { a + b }, [1, 2, 3, 4], 0)`, "[1, 3, 6, 10]"},
+		{`changanua(unda(a, b) { a + b }, [1, 2, 3, 4], 10)`, "[11, 13, 16, 20]"},
+		{`changanua(unda(a, b) { a + b }, [1, 2, 3, 4])`, "[1, 3, 6, 10]"},
+		{`fanya kubwa = unda(a, b) { kama (a > b) { a } sivyo { b } }; changanua(kubwa, [3, 1, 4, 1, 5, 2])`, "[3, 3, 4, 4, 5, 5]"},
+		{`changanua(unda(a, b) { a + b }, ["a", "b", "c"], "")`, "[a, ab, abc]"},
+		{`changanua(unda(a, b) { a + b }, [], 0)`, "[]"},
+		{`changanua(unda(a, b) { a + b }, [])`, "[]"},
+		{`changanua(unda(a, b) { a + b }, [7])`, "[7]"},
+		{`fanya o = [1, 2]; changanua(unda(a, b) { a * b }, o, 1); o`, "[1, 2]"},
+		{`changanua(unda(a, b) { a + b }, 5, 0)`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe ORODHA, sio NAMBA")},
+		{`changanua(unda(a, b) { a + b }, [1, "a"])`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + NENO")},
+		{`changanua(unda(a, b) { a + b })`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 2 au 3, wewe umeweka 1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestNumericMembership(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`1 ktk [1.0]`, true},
+		{`1.0 ktk [1]`, true},
+		{`1 ktk [1.0, 2.0]`, true},
+		{`2.5 ktk [1, 2, 3]`, false},
+		{`2.5 ktk [2.5]`, true},
+		{`3 ktk [1, 2, 3]`, true},
+		{`1 ktk ["1", kweli]`, false},
+		{`9007199254740993 ktk [9007199254740992]`, false},
+		{`1 si ktk [1.0]`, false},
+		{`2 si ktk [1.0]`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDeepGetBuiltin(t *testing.T) {
+	data := `fanya data = {"mtumiaji": {"jina": "Asha", "simu": ["0711", "0622"], "anwani": tupu}, 1: [[1, 2], {"x": kweli}]}; `
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{data + `pataNdani(data, ["mtumiaji", "jina"])`, "Asha"},
+		{data + `pataNdani(data, ["mtumiaji", "simu", 1])`, "0622"},
+		{data + `pataNdani(data, [1, 1, "x"])`, "kweli"},
+		{data + `pataNdani(data, [1, 0, 1])`, "2"},
+		{data + `pataNdani(data, [])`, "{mtumiaji: {jina: Asha, simu: [0711, 0622], anwani: null}, 1: [[1, 2], {x: kweli}]}"},
+		{data + `pataNdani(data, ["mtumiaji", "umri"])`, nil},
+		{data + `pataNdani(data, ["haipo", "jina", 0])`, nil},
+		{data + `pataNdani(data, ["mtumiaji", "anwani", "mtaa"])`, nil},
+		{data + `pataNdani(data, ["mtumiaji", "simu", 5])`, nil},
+		{data + `pataNdani(data, ["mtumiaji", "simu", -1])`, nil},
+		{data + `pataNdani(data, ["mtumiaji", "simu", "kwanza"])`, nil},
+		{data + `pataNdani(data, ["mtumiaji", "jina", 0])`, nil},
+		{data + `pataNdani(data, [[1]])`, nil},
+		{`pataNdani(tupu, ["a"])`, nil},
+		{`pataNdani({}, "a")`, errorMessage("Mstari 0: Samahani, njia lazima iwe ORODHA, sio NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDeepSetBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`wekaNdani({}, ["a", "b", "c"], 1)`, "{a: {b: {c: 1}}}"},
+		{`fanya d = {"a": {"x": 0}}; wekaNdani(d, ["a", "b"], 2); d`, "{a: {x: 0, b: 2}}"},
+		{`fanya d = {"a": {"b": 1}}; wekaNdani(d, ["a", "b"], 5)`, "{a: {b: 5}}"},
+		{`fanya d = {"a": tupu}; wekaNdani(d, ["a", "b"], 1)`, "{a: {b: 1}}"},
+		{`fanya d = {"orodha": [{"x": 1}, {"x": 2}]}; wekaNdani(d, ["orodha", 1, "x"], 20); d`, "{orodha: [{x: 1}, {x: 20}]}"},
+		{`fanya o = [[1, 2], [3, 4]]; wekaNdani(o, [1, 0], 30); o`, "[[1, 2], [30, 4]]"},
+		{`fanya d = {}; fanya r = wekaNdani(d, ["a"], 1); r == d`, "kweli"},
+		{`wekaNdani({"a": 1}, ["a", "b"], 2)`, errorMessage("Mstari 0: Samahani, hatua 0 ya njia (a) ni NAMBA, sio KAMUSI wala ORODHA")},
+		{`wekaNdani({"a": "neno"}, ["a", "b", "c"], 2)`, errorMessage("Mstari 0: Samahani, hatua 0 ya njia (a) ni NENO, sio KAMUSI wala ORODHA")},
+		{`wekaNdani([[1]], [0, 5], 2)`, errorMessage("Mstari 0: Index imezidi idadi ya elements")},
+		{`wekaNdani([[1]], [3, 0], 2)`, errorMessage("Mstari 0: Index imezidi idadi ya elements")},
+		{`wekaNdani([[1]], ["a", 0], 2)`, errorMessage("Mstari 0: Tafadhali tumia number, sio: NENO")},
+		{`wekaNdani(5, ["a"], 2)`, errorMessage("Mstari 0: Operesheni hii haiwezekani kwa: NAMBA")},
+		{`wekaNdani({}, [[1], "b"], 2)`, errorMessage("Mstari 0: Samahani, ORODHA haitumiki kama key")},
+		{`wekaNdani({}, [], 2)`, errorMessage("Mstari 0: Samahani, njia haina kitu")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestRoundingBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`zungushaChini(2.5)`, 2},
+		{`zungushaChini(-2.5)`, -3},
+		{`zungushaChini(2.0)`, 2},
+		{`zungushaJuu(2.5)`, 3},
+		{`zungushaJuu(-2.5)`, -2},
+		{`zungushaJuu(2.1)`, 3},
+		{`zungushaKaribu(2.5)`, 3},
+		{`zungushaKaribu(-2.5)`, -3},
+		{`zungushaKaribu(2.4)`, 2},
+		{`zungushaKaribu(-2.6)`, -3},
+		{`zungushaKaribu(2.5, "benki")`, 2},
+		{`zungushaKaribu(-2.5, "benki")`, -2},
+		{`zungushaKaribu(3.5, "benki")`, 4},
+		{`zungushaKaribu(2.6, "benki")`, 3},
+		{`zungushaChini(7)`, 7},
+		{`zungushaJuu(-7)`, -7},
+		{`zungushaKaribu(7, "benki")`, 7},
+		{`zungushaChini("2")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`zungushaKaribu(2.5, "juu")`, errorMessage(`Mstari 0: Samahani, njia ya kuzungusha inaweza kuwa "benki" tu, sio juu`)},
+		{`zungushaJuu()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+		{`zungushaChini(0.0 / 0.0)`, errorMessage("Mstari 0: Samahani, NaN haiwezi kuzungushwa")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	// arithmetic turns whole decimals into NAMBA, so a float this big has
+	// to be made here
+	env := object.NewEnvironment()
+	env.Set("kubwa", &object.Float{Value: 1e20})
+	program := parser.New(lexer.New(`zungushaJuu(kubwa)`)).ParseProgram()
+	testErrorObject(t, Eval(program, env), "Mstari 0: Samahani, 100000000000000000000 ni kubwa mno kuwa NAMBA")
+}
+
+func TestFloatCheckBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`niNaN(0.0 / 0.0)`, true},
+		{`niNaN(1.0 / 0.0)`, false},
+		{`niNaN(1.5)`, false},
+		{`niNaN(3)`, false},
+		{`niInf(1.0 / 0.0)`, true},
+		{`niInf(-1.0 / 0.0)`, true},
+		{`niInf(0.0 / 0.0)`, false},
+		{`niInf(1000.5)`, false},
+		{`niInf(5)`, false},
+		{`niKamili(5)`, true},
+		{`niKamili(-5)`, true},
+		{`niKamili(2.5)`, false},
+		{`niKamili(0.1 + 0.2)`, false},
+		{`niKamili(7.5 * 2)`, true},
+		{`niKamili(2.5 * 2.0)`, true},
+		{`niKamili(4.0)`, true},
+		{`niKamili(1.0 / 0.0)`, false},
+		{`niKamili(0.0 / 0.0)`, false},
+		{`niNaN("a")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`niKamili()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestSetConversionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`kwaSeti([3, 1, 3, 2, 1])`, "seti{3, 1, 2}"},
+		{`kwaSeti(["a", "b", "a", 1, kweli, 1])`, "seti{a, b, 1, kweli}"},
+		{`kwaSeti([])`, "seti{}"},
+		{`idadi(kwaSeti([1, 1, 2, 2, 3]))`, "3"},
+		{`kwaOrodha(kwaSeti([3, 1, 3, 2, 1]))`, "[3, 1, 2]"},
+		{`kwaOrodha(kwaSeti(kwaOrodha(kwaSeti(["x", "y", "x"]))))`, "[x, y]"},
+		{`fanya o = [2, 2, 1]; kwaSeti(o); o`, "[2, 2, 1]"},
+		{`2 ktk kwaSeti([1, 2])`, "kweli"},
+		{`5 ktk kwaSeti([1, 2])`, "sikweli"},
+		{`5 si ktk kwaSeti([1, 2])`, "kweli"},
+		{`[1] ktk kwaSeti([1])`, "sikweli"},
+		{`fanya m = []; kwa x ktk kwaSeti([5, 4, 5]) { m = sukuma(m, x) }; m`, "[5, 4]"},
+		{`aina(kwaSeti([]))`, "SETI"},
+		{`kwaSeti([1, [2]])`, errorMessage("Mstari 0: Samahani, kipengele 1 ni ORODHA, hakiwezi kuwekwa kwenye seti")},
+		{`kwaSeti([{"a": 1}])`, errorMessage("Mstari 0: Samahani, kipengele 0 ni KAMUSI, hakiwezi kuwekwa kwenye seti")},
+		{`kwaSeti("abc")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`kwaOrodha([1, 2])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestPowerAndFloorAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fanya x = 5; x **= 2; x", 25},
+		{"fanya x = 2; x **= 3; x **= 2; x", 64},
+		{"fanya x = 1.5; x **= 2; x", 2.25},
+		{"fanya x = 10; x ~/= 3; x", 3},
+		{"fanya x = -10; x ~/= 3; x", -4},
+		{"fanya x = 7.5; x ~/= 2; x", 3},
+		{"fanya o = [4, 9]; o[1] ~/= 2; o[0] **= 2; o[0] + o[1]", 20},
+		{"fanya x = 1; x ~/= 0", errorMessage("Mstari 0: Huwezi kugawanya kwa sifuri")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestCompoundAssignmentMatrix(t *testing.T) {
+	tests := []struct {
+		operator string
+		start    string
+		operand  string
+		expected string
+	}{
+		{"+=", "7", "2", "9"},
+		{"-=", "7", "2", "5"},
+		{"*=", "7", "2", "14"},
+		{"/=", "7", "2", "3.5"},
+		{"%=", "7", "2", "1"},
+		{"**=", "7", "2", "49"},
+		{"~/=", "7", "2", "3"},
+		{"+=", `"ab"`, `"c"`, "abc"},
+		{"*=", `"ab"`, "2", "abab"},
+		{"+=", "[1]", "[2]", "[1, 2]"},
+		{"-=", "7.5", "2", "5.5"},
+		{"**=", "2", "0.5", "1.4142135623730951"},
+		{"&&=", "kweli", "5", "5"},
+		{"&&=", "sikweli", "5", "sikweli"},
+		{"||=", "tupu", "5", "5"},
+		{"||=", "3", "5", "3"},
+	}
+
+	for _, tt := range tests {
+		input := fmt.Sprintf("fanya x = %s; x %s %s; x", tt.start, tt.operator, tt.operand)
+		if got := testEval(input).Inspect(); got != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", input, tt.expected, got)
+		}
+	}
+}
+
+// Every compound assignment token must lex from its operator followed by
+// =, and apply that same operator.
+func TestCompoundOperatorsMatchTokens(t *testing.T) {
+	for tokenType, infix := range compoundOperators {
+		tok := lexer.New(infix + "=").NextToken()
+		if tok.Type != tokenType {
+			t.Errorf("%s= lexed as %q, expected %q", infix, tok.Type, tokenType)
+		}
+	}
+
+	for _, literal := range []string{"+=", "-=", "*=", "/=", "%=", "**=", "~/="} {
+		tok := lexer.New(literal).NextToken()
+		if _, ok := compoundOperators[tok.Type]; !ok {
+			t.Errorf("%s has no operator to apply", literal)
+		}
+	}
+}
+
+func TestLogBuiltins(t *testing.T) {
+	var logs bytes.Buffer
+	SetLogOutput(&logs)
+	defer SetLogOutput(os.Stderr)
+
+	timestamp := `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(Z|[+-]\d{2}:\d{2})`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`logInfo("imeanza")`, `[INFO] imeanza`},
+		{`logOnyo("diski inajaa", {"nafasi": 10, "kitengo": "GB"})`, `[ONYO] diski inajaa nafasi=10 kitengo="GB"`},
+		{`logKosa("imeshindikana", {"jina": "faili yangu.txt", "tena": kweli, "orodha": [1, 2]})`, `[KOSA] imeshindikana jina="faili yangu.txt" tena=kweli orodha=[1, 2]`},
+		{`logInfo(42, {})`, `[INFO] 42`},
+	}
+
+	for _, tt := range tests {
+		logs.Reset()
+		evaluated := testEval(tt.input)
+		if evaluated != NULL {
+			t.Errorf("%s: expected tupu, got=%s", tt.input, evaluated.Inspect())
+		}
+
+		pattern := regexp.MustCompile("^" + timestamp + " " + regexp.QuoteMeta(tt.expected) + "\n$")
+		if !pattern.MatchString(logs.String()) {
+			t.Errorf("%s: wrong log line %q", tt.input, logs.String())
+		}
+	}
+
+	logs.Reset()
+	testErrorObject(t, testEval(`logInfo("a", [1])`), "Mstari 0: Samahani, sehemu lazima ziwe KAMUSI, sio ORODHA")
+	testErrorObject(t, testEval(`logKosa()`), "Mstari 0: Samahani, tunahitaji Hoja 1 au 2, wewe umeweka 0")
+	if logs.Len() != 0 {
+		t.Errorf("nothing should be logged on an error, got=%q", logs.String())
+	}
+}
+
+func TestUnganishaKwaKina(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`unganishaKwaKina({"a": 1}, {"b": 2})`, `{a: 1, b: 2}`},
+		{`unganishaKwaKina({"a": {"x": 1, "y": 2}}, {"a": {"y": 3, "z": 4}})`, `{a: {x: 1, y: 3, z: 4}}`},
+		{`unganishaKwaKina({"a": {"b": {"c": 1}}}, {"a": {"b": {"d": 2}}})`, `{a: {b: {c: 1, d: 2}}}`},
+		{`unganishaKwaKina({"a": {"x": 1}}, {"a": 5})`, `{a: 5}`},
+		{`unganishaKwaKina({"a": 5}, {"a": {"x": 1}})`, `{a: {x: 1}}`},
+		{`unganishaKwaKina({"a": [1, 2]}, {"a": [3]})`, `{a: [3]}`},
+		{`unganishaKwaKina({}, {})`, `{}`},
+		{`unganishaKwaKina({"a": 1}, [1])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+		{`unganishaKwaKina({"a": 1})`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 1")},
+		{`fanya d = {"a": 1}; d["self"] = d; unganishaKwaKina(d, {})`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kunakiliwa")},
+		{`fanya d = {"a": 1}; d["self"] = d; unganishaKwaKina({"self": {"a": 2}}, d)`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kunakiliwa")},
+		{`fanya d = {}; fanya o = [d]; d["o"] = o; unganishaKwaKina({"x": d}, {})`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kunakiliwa")},
+		{`fanya s = {"x": 1}; unganishaKwaKina({"a": s, "b": [s, s]}, {})`, `{a: {x: 1}, b: [{x: 1}, {x: 1}]}`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestUnganishaKwaKinaDoesNotAlias(t *testing.T) {
+	input := `
+	fanya a = {"ndani": {"x": 1}, "orodha": [1]}
+	fanya b = {"ndani": {"y": 2}, "nyingine": {"z": 3}}
+	fanya c = unganishaKwaKina(a, b)
+	c["ndani"]["x"] = 100
+	c["nyingine"]["z"] = 300
+	c["orodha"][0] = 9
+	fanya matokeo = [a, b, c]
+	matokeo
+	`
+	expected := `[{ndani: {x: 1}, orodha: [1]}, {ndani: {y: 2}, nyingine: {z: 3}}, {ndani: {x: 100, y: 2}, orodha: [9], nyingine: {z: 300}}]`
+
+	evaluated := testEval(input)
+	if evaluated.Inspect() != expected {
+		t.Errorf("expected %s, got=%s", expected, evaluated.Inspect())
+	}
+}
+
+func TestDeepCopySetsAndBuilders(t *testing.T) {
+	set := &object.Set{}
+	set.Add(&object.String{Value: "a"})
+	builder := &object.Builder{Array: true}
+	builder.Add(&object.Array{Elements: []object.Object{newInteger(1)}})
+
+	for _, obj := range []object.Object{set, builder} {
+		copied, err := deepCopy(0, obj)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Message)
+		}
+		if copied == obj {
+			t.Errorf("%s should be copied, not shared", obj.Type())
+		}
+		if copied.Inspect() != obj.Inspect() {
+			t.Errorf("expected %s, got=%s", obj.Inspect(), copied.Inspect())
+		}
+	}
+
+	copied, _ := deepCopy(0, builder)
+	inner := copied.(*object.Builder).Result().(*object.Array).Elements[0]
+	if inner == builder.Result().(*object.Array).Elements[0] {
+		t.Errorf("the elements of a builder should be copied too")
+	}
+}
+
+func TestTafutaKwanza(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`tafutaKwanza([1, 4, 6, 8], unda(x) { x % 2 == 0 })`, 4},
+		{`tafutaKwanza([1, 3, 5], unda(x) { x > 10 })`, nil},
+		{`tafutaKwanza([], unda(x) { kweli })`, nil},
+		{`tafutaKwanza(["a", "bb", "ccc"], unda(x) { idadi(x) > 1 })`, "bb"},
+		{`tafutaKwanza([1, 2], unda(x) { x + kweli })`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + BOOLEAN")},
+		{`tafutaKwanza("abc", unda(x) { kweli })`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe ORODHA, sio NENO")},
+		{`tafutaKwanza([1])`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestTafutaKwanzaShortCircuits(t *testing.T) {
+	input := `
+	fanya kaguliwa = {"idadi": 0}
+	fanya tokeo = tafutaKwanza([1, 2, 3, 4, 5], unda(x) {
+		kaguliwa["idadi"] += 1
+		x == 2
+	})
+	fanya matokeo = [tokeo, kaguliwa["idadi"]]
+	matokeo
+	`
+	evaluated := testEval(input)
+	if evaluated.Inspect() != "[2, 2]" {
+		t.Errorf("expected [2, 2], got=%s", evaluated.Inspect())
+	}
+}
+
+func TestKipengee(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`kipengee([10, 20, 30], 0, -1)`, 10},
+		{`kipengee([10, 20, 30], 2, -1)`, 30},
+		{`kipengee([10, 20, 30], 3, -1)`, -1},
+		{`kipengee([10, 20, 30], 100, "hakuna")`, "hakuna"},
+		{`kipengee([], 0, 7)`, 7},
+		{`kipengee([10, 20, 30], -1, 0)`, 30},
+		{`kipengee([10, 20, 30], -3, 0)`, 10},
+		{`kipengee([10, 20, 30], -4, 0)`, 0},
+		{`kipengee([10, 20, 30], -100, tupu)`, nil},
+		{`kipengee([10, 20], "a", 0)`, errorMessage("Mstari 0: Tafadhali tumia number, sio: NENO")},
+		{`kipengee("abc", 0, 0)`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe ORODHA, sio NENO")},
+		{`kipengee([1], 0)`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 3, wewe umeweka 2")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input  string
+		folded string
+	}{
+		{"2 * 60 * 60", "7200"},
+		{"1 + 2 * 3", "7"},
+		{"-5 + 2", "-3"},
+		{"2 ** 10", "1024"},
+		{"7 ~/ 2", "3"},
+		{"1.5 * 2", "3"},
+		{"0.1 + 0.2", "0.30000000000000004"},
+		{"x * 60 * 60", "((x * 60) * 60)"},
+		{"x * (60 * 60)", "(x * 3600)"},
+		{"f(2 * 3)", "f(6)"},
+		{"fanya a = [1 + 1, 2 * 2]", "fanya a = [2, 4];"},
+		{"kwa i ktk [1] { i * (2 + 3) }", "kwa i ktk [1] {\n\t(i * 5)\n}"},
+		{"1 / 0", "+Inf"},
+		{"10 % 0", "(10 % 0)"},
+		{"1 + kweli", "(1 + kweli)"},
+		{`"a" + "b"`, "(a + b)"},
+		{"1 < 2", "(1 < 2)"},
+		{"!5", "(!5)"},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		if actual := Fold(program).String(); actual != tt.folded {
+			t.Errorf("%s: expected %q, got=%q", tt.input, tt.folded, actual)
+		}
+	}
+}
+
+func TestFoldEvaluatesIdentically(t *testing.T) {
+	inputs := []string{
+		"2 * 60 * 60",
+		"fanya x = 3; x * (4 - 1) ** 2",
+		"9223372036854775807 + 1",
+		"1.5 * 2 + 0.25",
+		"-(2 * 3.5)",
+		"17 % 5 * 3 ~/ 2",
+		"1 / 0",
+		"[1 + 1, 2 * 2][1 - 1]",
+		`{"a": 1 + 2}["a"] * 2`,
+		"fanya f = unda(n) { rudisha n * (24 * 60) }; f(2)",
+		"fanya jumla = 0; kwa i ktk [1, 2, 3] { jumla += i * (10 * 10) }; jumla",
+	}
+
+	for _, input := range inputs {
+		plain := testEval(input)
+		program := parser.New(lexer.New(input)).ParseProgram()
+		folded := Eval(Fold(program), object.NewEnvironment())
+		if plain.Inspect() != folded.Inspect() || plain.Type() != folded.Type() {
+			t.Errorf("%s: folding changed the result from %s %s to %s %s",
+				input, plain.Type(), plain.Inspect(), folded.Type(), folded.Inspect())
+		}
+	}
+}
+
+func BenchmarkUnfoldedConstants(b *testing.B) {
+	benchmarkConstants(b, func(program *ast.Program) *ast.Program { return program })
+}
+
+func BenchmarkFoldedConstants(b *testing.B) {
+	benchmarkConstants(b, Fold)
+}
+
+func benchmarkConstants(b *testing.B, prepare func(*ast.Program) *ast.Program) {
+	program := prepare(parser.New(lexer.New(`
+	fanya jumla = 0
+	kwa i ktk orodha { jumla += i * (24 * 60 * 60) + (1000 - 1) }
+	jumla
+	`)).ParseProgram())
+
+	elements := make([]object.Object, 10000)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	arr := &object.Array{Elements: elements}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		env.Set("orodha", arr)
+		Eval(program, env)
+	}
+}
+
+func TestBuiltinCacheRespectsShadowing(t *testing.T) {
+	input := `
+	fanya hesabu = unda() { idadi([1, 2, 3]) }
+	fanya kabla = hesabu()
+	fanya idadi = unda(x) { 42 }
+	fanya matokeo = [kabla, hesabu()]
+	matokeo
+	`
+	evaluated := testEval(input)
+	if evaluated.Inspect() != "[3, 42]" {
+		t.Errorf("expected [3, 42], got=%s", evaluated.Inspect())
+	}
+}
+
+func TestBuiltinCacheRespectsSandbox(t *testing.T) {
+	program := parser.New(lexer.New(`idadi([1, 2])`)).ParseProgram()
+
+	testIntegerObject(t, Eval(program, NewEnvironment()), 2)
+	testErrorObject(t, Eval(program, NewSandboxEnv([]string{"andika"})), "Mstari 0: Neno Halifahamiki: idadi")
+	testIntegerObject(t, Eval(program, NewSandboxEnv([]string{"idadi"})), 2)
+}
+
+func TestBuiltinCacheConcurrent(t *testing.T) {
+	program := parser.New(lexer.New(`fanya f = unda(n) { kama (n == 0) { idadi([1, 2]) } sivyo { f(n - 1) } }; f(20)`)).ParseProgram()
+
+	var wg sync.WaitGroup
+	results := make([]object.Object, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			env := NewEnvironment()
+			if i%2 == 1 {
+				env = NewSandboxEnv([]string{"andika"})
+			}
+			results[i] = Eval(program, env)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if i%2 == 1 {
+			testErrorObject(t, result, "Mstari 0: Neno Halifahamiki: idadi")
+			continue
+		}
+		testIntegerObject(t, result, 2)
+	}
+}
+
+func BenchmarkBuiltinCalls(b *testing.B) {
+	program := parser.New(lexer.New(`kwa i ktk orodha { idadi(orodha) }`)).ParseProgram()
+
+	elements := make([]object.Object, 10000)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	arr := &object.Array{Elements: elements}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		env.Set("orodha", arr)
+		Eval(program, env)
+	}
+}
+
+func TestSmallIntegersAreShared(t *testing.T) {
+	if testEval("1 + 1") != testEval("2") {
+		t.Errorf("small integers should be shared")
+	}
+	if testEval("300 + 1") == testEval("301") {
+		t.Errorf("integers outside the small range should not be shared")
+	}
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fanya a = 5; fanya b = 5; a++; b", 5},
+		{"fanya a = 5; fanya b = a; a += 1; b", 5},
+		{"fanya a = [1, 1]; a[0] = 2; a[1]", 1},
+		{"fanya a = -128; a--; a", -129},
+		{"fanya a = 255; a++; a", 256},
+		{"fanya a = 200 + 55; a - 1", 254},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func BenchmarkNumericLoop(b *testing.B) {
+	program := parser.New(lexer.New(`
+	fanya jumla = 0
+	kwa i ktk orodha { jumla = (jumla + i % 7 * 3) % 100 }
+	jumla
+	`)).ParseProgram()
+
+	elements := make([]object.Object, 10000)
+	for i := range elements {
+		elements[i] = &object.Integer{Value: int64(i)}
+	}
+	arr := &object.Array{Elements: elements}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		env := object.NewEnvironment()
+		env.Set("orodha", arr)
+		Eval(program, env)
+	}
+}
+
+func TestRecursiveFunctionEnvironments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fanya fib = unda(n) { kama (n < 2) { rudisha n }; rudisha fib(n - 1) + fib(n - 2) }; fib(15)", 610},
+		{"fanya jumla = unda(n, acc) { kama (n == 0) { rudisha acc }; rudisha jumla(n - 1, acc + n) }; jumla(100, 0)", 5050},
+		{"fanya ongeza = unda(x) { unda(y) { x + y } }; fanya a = ongeza(1); fanya b = ongeza(10); a(5) + b(5)", 21},
+		{"fanya f = unda(n) { fanya ndani = n * 2; kama (n > 0) { f(n - 1) }; ndani }; f(5)", 10},
+	}
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func BenchmarkRecursiveCalls(b *testing.B) {
+	program := parser.New(lexer.New(`
+	fanya fib = unda(n) { kama (n < 2) { rudisha n }; rudisha fib(n - 1) + fib(n - 2) }
+	fib(18)
+	`)).ParseProgram()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Eval(program, object.NewEnvironment())
+	}
+}
+
+func TestUrefuMkubwaNaMdogo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`urefuMkubwa(["a", "abc", "ab"])`, 3},
+		{`urefuMdogo(["a", "abc", "ab"])`, 1},
+		{`urefuMkubwa(["nuru"])`, 4},
+		{`urefuMdogo(["", "a"])`, 0},
+		{`urefuMkubwa(["café", "abcd", "ab"])`, 4},
+		{`urefuMkubwa(["🌍🌍", "abc"])`, 3},
+		{`urefuMdogo(["🌍🌍", "abc"])`, 2},
+		{`urefuMkubwa(["ñandú", "jambo!"])`, 6},
+		{`urefuMkubwa([])`, errorMessage("Mstari 0: Samahani, orodha haina kitu")},
+		{`urefuMdogo(["a", 1])`, errorMessage("Mstari 0: Samahani, orodha lazima iwe na NENO tu, sio NAMBA")},
+		{`urefuMkubwa("abc")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`urefuMdogo()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestErrorMessageHasNoColorCodes(t *testing.T) {
+	object.SetColor(false)
+	defer object.SetColor(true)
+
+	evaluated := testEval(`1 + kweli`)
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got=%T", evaluated)
+	}
+	if strings.Contains(errObj.Message, "\x1b") || strings.Contains(errObj.Inspect(), "\x1b") {
+		t.Errorf("expected no escape codes, got message=%q inspect=%q", errObj.Message, errObj.Inspect())
+	}
+	if errObj.Inspect() != "Kosa: Mstari 0: Aina Hazilingani: NAMBA + BOOLEAN" {
+		t.Errorf("wrong error, got=%q", errObj.Inspect())
+	}
+}
+
+func TestRudiaFn(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`rudiaFn(4, unda(i) { i * i })`, "[0, 1, 4, 9]"},
+		{`rudiaFn(3, unda() { "x" })`, "[x, x, x]"},
+		{`rudiaFn(0, unda(i) { i })`, "[]"},
+		{`fanya hesabu = {"n": 0}; rudiaFn(5, unda() { hesabu["n"] += 1 }); hesabu["n"]`, 5},
+		{`rudiaFn(2, aina)`, "[NAMBA, NAMBA]"},
+		{`rudiaFn(-1, unda(i) { i })`, errorMessage("Mstari 0: Samahani, idadi ya marudio haiwezi kuwa hasi: -1")},
+		{`rudiaFn(9223372036854775807, unda(i) { kama (i == 2) { 1 + "a" } })`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + NENO")},
+		{`rudiaFn("a", unda(i) { i })`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe NAMBA, sio NENO")},
+		{`rudiaFn(2, unda(i) { i + kweli })`, errorMessage("Mstari 0: Aina Hazilingani: NAMBA + BOOLEAN")},
+		{`rudiaFn(2)`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestDictLiteralComputedKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fanya a = 1; {a + 1: "x", "k" + "ey": 2}`, "{2: x, key: 2}"},
+		{`fanya f = unda() { "jina" }; {f(): "Asha"}`, "{jina: Asha}"},
+		{`{"a": 1, "b": 2, "a": 3}`, "{a: 3, b: 2}"},
+		{`fanya a = 1; {a: "moja", 1: "tena", 2: "mbili"}`, "{1: tena, 2: mbili}"},
+		{`fanya a = 1; {a * 2: "x", 2: "y", 1 + 1: "z"}`, "{2: z}"},
+		{`{kweli: 1, 1 > 0: 2}`, "{kweli: 2}"},
+	}
+
+	for _, tt := range tests {
+		// the order must be the same on every run, not just most of them
+		for i := 0; i < 20; i++ {
+			evaluated := testEval(tt.input)
+			if evaluated.Inspect() != tt.expected {
+				t.Fatalf("%s: expected %s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+			}
+		}
+	}
+
+	testErrorObject(t, testEval(`{[1]: 2}`), "Mstari 0: Hashing imeshindikana: ORODHA")
+}
+
+func TestKeyYaKubwaNaNdogo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`keyYaKubwa({"a": 3, "b": 7, "c": 5})`, "b"},
+		{`keyYaNdogo({"a": 3, "b": 7, "c": 5})`, "a"},
+		{`keyYaKubwa({"a": 1.5, "b": 2, "c": -1})`, "b"},
+		{`keyYaNdogo({"a": 1.5, "b": 2, "c": -1})`, "c"},
+		{`keyYaKubwa({"x": "ndizi", "y": "embe", "z": "papai"})`, "z"},
+		{`keyYaNdogo({"x": "ndizi", "y": "embe", "z": "papai"})`, "y"},
+		{`keyYaKubwa({"a": 7, "b": 7, "c": 1})`, "a"},
+		{`keyYaNdogo({"a": 7, "b": 1, "c": 1})`, "b"},
+		{`keyYaKubwa({1: 10, 2: 20})`, 2},
+		{`keyYaKubwa({})`, errorMessage("Mstari 0: Samahani, kamusi haina kitu")},
+		{`keyYaKubwa({"a": [1]})`, errorMessage("Mstari 0: Samahani, thamani lazima ziwe NAMBA, DESIMALI au NENO, sio ORODHA")},
+		{`keyYaNdogo({"a": 1, "b": "c"})`, errorMessage("Mstari 0: Aina Hazilingani: NENO < NAMBA")},
+		{`keyYaKubwa([1])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestBapaNaFumua(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`bapa({"a": {"b": {"c": 1}, "d": 2}, "e": 3})`, "{a.b.c: 1, a.d: 2, e: 3}"},
+		{`bapa({"a": [1, {"b": 2}], "c": {}})`, "{a: [1, {b: 2}], c: {}}"},
+		{`bapa({})`, "{}"},
+		{`fumua({"a.b.c": 1, "a.d": 2, "e": 3})`, "{a: {b: {c: 1}, d: 2}, e: 3}"},
+		{`fumua({"c": {}})`, "{c: {}}"},
+		{`fumua({})`, "{}"},
+		{`bapa({"a.b": 1})`, errorMessage("Mstari 0: Samahani, key 'a.b' haiwezi kuwa tupu wala kuwa na nukta")},
+		{`fanya d = {"a": 1}; d["b"] = {"c": d}; bapa(d)`, errorMessage("Mstari 0: Samahani, muundo unajirudia kwenye njia 'b.c'")},
+		{`fanya d = {"a": 1}; d["b"] = [d]; bapa(d)`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kunakiliwa")},
+		{`fanya s = {"x": 1}; bapa({"a": s, "b": s})`, "{a.x: 1, b.x: 1}"},
+		{`bapa({"a": {"": 1}})`, errorMessage("Mstari 0: Samahani, key '' haiwezi kuwa tupu wala kuwa na nukta")},
+		{`bapa({1: 2})`, errorMessage("Mstari 0: Samahani, key lazima iwe NENO, sio NAMBA")},
+		{`fumua({"a": 1, "a.b": 2})`, errorMessage("Mstari 0: Samahani, njia 'a.b' inagongana na njia nyingine")},
+		{`fumua({"a.b": 1, "a": 2})`, errorMessage("Mstari 0: Samahani, njia 'a' inagongana na njia nyingine")},
+		{`fumua({"a..b": 1})`, errorMessage("Mstari 0: Samahani, njia 'a..b' si sahihi")},
+		{`fumua({1: 2})`, errorMessage("Mstari 0: Samahani, njia lazima iwe NENO, sio NAMBA")},
+		{`bapa([1])`, errorMessage("Mstari 0: Samahani, hii function haitumiki na ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestBapaFumuaRoundTrip(t *testing.T) {
+	input := `
+	fanya mipangilio = {
+		"seva": {"jina": "nuru", "bandari": 8080, "tls": {"ipo": kweli, "vyeti": ["a.pem", "b.pem"]}},
+		"hifadhi": {"njia": "/tmp", "ziada": {}},
+		"toleo": 2.5
+	}
+	fanya rudi = fumua(bapa(mipangilio))
+	rudi["seva"]["tls"]["vyeti"][0] = "mpya.pem"
+	fanya matokeo = [mipangilio["seva"]["tls"]["vyeti"][0], rudi["hifadhi"]]
+	matokeo
+	`
+	evaluated := testEval(input)
+	expected := "[a.pem, {njia: /tmp, ziada: {}}]"
+	if evaluated.Inspect() != expected {
+		t.Errorf("expected %s, got=%s", expected, evaluated.Inspect())
+	}
+
+	input = `
+	fanya mipangilio = {"a": {"b": {"c": 1, "d": [1, 2]}, "e": "x"}, "f": tupu}
+	fumua(bapa(mipangilio))
+	`
+	evaluated = testEval(input)
+	if evaluated.Inspect() != testEval(`{"a": {"b": {"c": 1, "d": [1, 2]}, "e": "x"}, "f": tupu}`).Inspect() {
+		t.Errorf("round trip changed the dict, got=%s", evaluated.Inspect())
+	}
+}
+
+func TestDesimaliGawanya(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`desimaliGawanya(4, 2)`, 2.0},
+		{`desimaliGawanya(5, 2)`, 2.5},
+		{`desimaliGawanya(-7, 2)`, -3.5},
+		{`desimaliGawanya(1.5, 0.5)`, 3.0},
+		{`desimaliGawanya(0, 5)`, 0.0},
+		{`desimaliGawanya(1, "a")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`desimaliGawanya(1)`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 1")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	// plain / keeps giving a whole number when it can
+	testIntegerObject(t, testEval(`4 / 2`), 2)
+	testFloatObject(t, testEval(`5 / 2`), 2.5)
+}
+
+func TestForInDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya s = ""; kwa [k, v] ktk vipengele({"a": "x", "b": "y"}) { s += k + "=" + v + ";" }; s`, "a=x;b=y;"},
+		{`fanya jumla = 0; kwa [a, b, c] ktk [[1, 2, 3], [4, 5, 6]] { jumla += a * b - c }; jumla`, 13},
+		{`fanya s = 0; kwa i, [a, b] ktk [[1, 2], [3, 4]] { s += i * 100 + a * b }; s`, 114},
+		{`fanya a = "nje"; kwa [a, b] ktk [[1, 2]] { }; a`, "nje"},
+		{`fanya s = 0; kwa [a, b] ktk [[1, 2], [3, 4], [5, 6]] { kama (a == 3) { endelea }; s += b }; s`, 8},
+		{`kwa [a, b] ktk [[1, 2], [3]] { }`, errorMessage("Mstari 0: Samahani, [a, b] inahitaji vitu 2, lakini orodha ina vitu 1")},
+		{`kwa [a, b] ktk [[1, 2, 3]] { }`, errorMessage("Mstari 0: Samahani, [a, b] inahitaji vitu 2, lakini orodha ina vitu 3")},
+		{`kwa [a, b] ktk [1, 2] { }`, errorMessage("Mstari 0: Samahani, [a, b] inahitaji ORODHA, sio NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestPindua(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`pindua([[1, 2], [3, 4]])`, "[[1, 3], [2, 4]]"},
+		{`pindua([[1, 2, 3], [4, 5, 6]])`, "[[1, 4], [2, 5], [3, 6]]"},
+		{`pindua([[1], [2], [3]])`, "[[1, 2, 3]]"},
+		{`pindua(pindua([[1, 2, 3], [4, 5, 6]]))`, "[[1, 2, 3], [4, 5, 6]]"},
+		{`pindua([])`, "[]"},
+		{`pindua([[], []])`, "[]"},
+		{`pindua([[1, 2], [3]])`, errorMessage("Mstari 0: Samahani, safu 1 ina vitu 1 lakini safu 0 ina vitu 2")},
+		{`pindua([[1], [2, 3]])`, errorMessage("Mstari 0: Samahani, safu 1 ina vitu 2 lakini safu 0 ina vitu 1")},
+		{`pindua([[1], 2])`, errorMessage("Mstari 0: Samahani, kila safu lazima iwe ORODHA, safu 1 ni NAMBA")},
+		{`pindua("abc")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestParseJsoni(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`parsejsoni("1")`, 1},
+		{`parsejsoni("-42")`, -42},
+		{`parsejsoni("1.0")`, 1.0},
+		{`parsejsoni("2.5")`, 2.5},
+		{`parsejsoni("1e2")`, 100.0},
+		{`parsejsoni("9223372036854775808")`, 9223372036854775808.0},
+		{`aina(parsejsoni("1"))`, "NAMBA"},
+		{`aina(parsejsoni("1.0"))`, "DESIMALI"},
+		{`parsejsoni("\"habari\"")`, "habari"},
+		{`parsejsoni("1 2")`, errorMessage("Mstari 0: Samahani, JSON si sahihi: kuna maandishi zaidi baada ya thamani")},
+		{`parsejsoni(1)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	evaluated := testEval(`parsejsoni("{\"z\": 1, \"a\": [1, 2.0, true, null], \"m\": {\"x\": \"y\"}}")`)
+	if evaluated.Inspect() != "{z: 1, a: [1, 2, kweli, null], m: {x: y}}" {
+		t.Errorf("wrong object, got=%s", evaluated.Inspect())
+	}
+	testNullObject(t, testEval(`parsejsoni("null")`))
+
+	// the rest of these messages come from encoding/json
+	for _, input := range []string{`parsejsoni("kweli")`, `parsejsoni("[1, 2")`, `parsejsoni("{1: 2}")`, `parsejsoni("")`} {
+		errObj, ok := testEval(input).(*object.Error)
+		if !ok || !strings.HasPrefix(errObj.Message, "Mstari 0: Samahani, JSON si sahihi: ") {
+			t.Errorf("%s: expected a JSON error, got=%v", input, errObj)
+		}
+	}
+}
+
+func TestJsoni(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`jsoni(1)`, "1"},
+		{`jsoni(1.0)`, "1.0"},
+		{`jsoni(2.5)`, "2.5"},
+		{`jsoni(-3)`, "-3"},
+		{`jsoni("a \"b\"\n")`, `"a \"b\"\n"`},
+		{`jsoni([1, 2.0, kweli, tupu])`, "[1,2.0,true,null]"},
+		{`jsoni({"z": 1, "a": {"b": []}})`, `{"z":1,"a":{"b":[]}}`},
+		{`jsoni({1: 2})`, errorMessage("Mstari 0: Samahani, key za JSON lazima ziwe NENO, sio NAMBA")},
+		{`jsoni(unda() {})`, errorMessage("Mstari 0: Samahani, UNDO (FUNCTION) haiwezi kuwa JSON")},
+		{`fanya d = {"a": 1}; d["self"] = d; jsoni(d)`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kuwa JSON")},
+		{`fanya o = [1]; o[0] = [o]; jsoni(o)`, errorMessage("Mstari 0: Samahani, muundo unajirudia, hauwezi kuwa JSON")},
+		{`fanya s = {"x": 1}; jsoni([s, s])`, `[{"x":1},{"x":1}]`},
+		{`jsoni(1 / 0)`, errorMessage("Mstari 0: Samahani, JSON haiwezi kuwa na +Inf")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			testStringObject(t, evaluated, expected)
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestJsoniRoundTrip(t *testing.T) {
+	input := `
+	fanya asili = {"nzima": 3, "desimali": 3.0, "nusu": 0.5, "orodha": [1, 1.0, -2, -2.0]}
+	fanya rudi = parsejsoni(jsoni(asili))
+	fanya matokeo = [
+		aina(rudi["nzima"]), aina(rudi["desimali"]), aina(rudi["nusu"]),
+		aina(rudi["orodha"][0]), aina(rudi["orodha"][1]), aina(rudi["orodha"][2]), aina(rudi["orodha"][3]),
+		jsoni(rudi) == jsoni(asili)
+	]
+	matokeo
+	`
+	evaluated := testEval(input)
+	expected := "[NAMBA, DESIMALI, DESIMALI, NAMBA, DESIMALI, NAMBA, DESIMALI, kweli]"
+	if evaluated.Inspect() != expected {
+		t.Errorf("expected %s, got=%s", expected, evaluated.Inspect())
+	}
+}
+
+func TestOptionalAccess(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya a = tupu; a?.jina`, nil},
+		{`fanya a = tupu; a?[0]`, nil},
+		{`fanya a = tupu; a?[1:3]`, nil},
+		{`fanya a = {"mtu": {"jina": "Asha"}}; a?.mtu?.jina`, "Asha"},
+		{`fanya a = {"mtu": {"jina": "Asha"}}; a?.kazi?.jina`, nil},
+		{`fanya a = {"orodha": [1, 2]}; a?.orodha?[1]`, 2},
+		{`fanya a = {}; a?.orodha?[1]`, nil},
+		{`fanya a = [10, 20]; a?[0]`, 10},
+		{`fanya a = [10, 20]; a?[5]`, nil},
+		{`fanya a = [1, 2, 3]; a?[1:]`, "[2, 3]"},
+		{`parsejsoni("{\"mtu\": null}")?.mtu?.jina`, nil},
+		{`fanya hesabu = {"n": 0}; fanya f = unda() { hesabu["n"] += 1; 0 }; fanya a = tupu; a?[f()]; hesabu["n"]`, 0},
+		{`fanya a = tupu; a.jina`, errorMessage("Mstari 0: Huwezi kutumia '.jina' na TUPU")},
+		{`fanya a = tupu; a?.b.c`, errorMessage("Mstari 0: Huwezi kutumia '.c' na TUPU")},
+		{`fanya a = 5; a?.jina`, errorMessage("Mstari 0: Huwezi kutumia '.jina' na NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestMarudioBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`marudio(["b", "a", "b", "c", "b", "a"])`, "{b: 3, a: 2, c: 1}"},
+		{`marudio([3, 1, 3, kweli, 3])`, "{3: 3, 1: 1, kweli: 1}"},
+		{`marudio([])`, "{}"},
+		{`marudio([1, [2]])`, errorMessage("Mstari 0: Samahani, kipengele 1 ni ORODHA, hakiwezi kuhesabiwa")},
+		{`marudio("abc")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`marudio()`, errorMessage("Mstari 0: Samahani, tunahitaji Hoja 1, wewe umeweka 0")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestTengenezaBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`tengeneza("Habari {{jina}}, una miaka {{umri}}", {"jina": "Asha", "umri": 20})`, "Habari Asha, una miaka 20"},
+		{`tengeneza("{{ jina }}!", {"jina": "Juma"})`, "Juma!"},
+		{`tengeneza("{{x}} + {{x}} = {{y}}", {"x": 2, "y": 4})`, "2 + 2 = 4"},
+		{`tengeneza("Habari {{jina}} {{ukoo}}", {"jina": "Asha"})`, "Habari Asha {{ukoo}}"},
+		{`tengeneza("{{orodha}} {{kamusi}}", {"orodha": [1, 2], "kamusi": {"a": 1}})`, "[1, 2] {a: 1}"},
+		{`tengeneza("{{a}} {{ haijafungwa", {"a": 1})`, "1 {{ haijafungwa"},
+		{`tengeneza("", {})`, ""},
+		{`tengeneza(1, {})`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe NENO, sio NAMBA")},
+		{`tengeneza("{{a}}", [1])`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe KAMUSI, sio ORODHA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("%s: object is not String, got=%T(%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("%s: expected %q, got=%q", tt.input, expected, str.Value)
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestKikomoBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`kikomo([3, -1, 7, 2])`, "[-1, 7]"},
+		{`kikomo([5])`, "[5, 5]"},
+		{`kikomo([2.5, 1, 4])`, "[1, 4]"},
+		{`kikomo([1, 0.5, 3])`, "[0.5, 3]"},
+		{`kikomo([])`, errorMessage("Mstari 0: Samahani, orodha tupu haina ndogo wala kubwa")},
+		{`kikomo([1, "a"])`, errorMessage("Mstari 0: Samahani, kipengele 1 ni NENO, namba tu zinahitajika")},
+		{`kikomo(1)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+
+	mixed := testEval(`kikomo([2.5, 1, 4])`).(*object.Array)
+	for _, elem := range mixed.Elements {
+		if _, ok := elem.(*object.Float); !ok {
+			t.Errorf("mixed bounds should be Float, got=%T", elem)
+		}
+	}
+	ints := testEval(`kikomo([3, 1])`).(*object.Array)
+	testIntegerObject(t, ints.Elements[0], 1)
+	testIntegerObject(t, ints.Elements[1], 3)
+}
+
+func TestBlockExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`fanya x = kizuizi { fanya a = 2; fanya b = 3; toa a * b }; x`, 6},
+		{`fanya x = kizuizi { toa 1; 2 }; x`, 1},
+		{`fanya x = kizuizi { fanya a = 4; a + 1 }; x`, 5},
+		{`fanya x = kizuizi { }; x`, nil},
+		{`fanya x = kizuizi { toa }; x`, nil},
+		{`fanya n = 7; fanya x = kizuizi { kama (n > 5) { toa "kubwa" }; toa "ndogo" }; x`, "kubwa"},
+		{`fanya n = 3; fanya x = kizuizi { kama (n > 5) { toa "kubwa" }; toa "ndogo" }; x`, "ndogo"},
+		{`fanya x = kizuizi { kwa i ktk [4, 5, 6] { kama (i % 5 == 0) { toa i } }; toa -1 }; x`, 5},
+		{`fanya i = 0; fanya x = kizuizi { wakati (kweli) { i++; kama (i == 3) { toa i * 10 } } }; x`, 30},
+		{`fanya x = kizuizi { toa kizuizi { toa 2 } + 1 }; x`, 3},
+		{`fanya x = kizuizi { kwa i ktk [1, 2, 3] { kama (i == 2) { vunja } }; toa "sawa" }; x`, "sawa"},
+		{`fanya f = unda() { fanya x = kizuizi { rudisha 9 }; 0 }; f()`, errorMessage("Mstari 0: 'rudisha' haiwezi kutoka nje ya kizuizi, tumia 'toa'")},
+		{`kwa i ktk [1, 2] { fanya x = kizuizi { endelea } }`, errorMessage("Mstari 0: 'endelea' haiwezi kutoka nje ya kizuizi, tumia 'toa'")},
+		{`wakati (kweli) { fanya x = kizuizi { vunja } }`, errorMessage("Mstari 0: 'vunja' haiwezi kutoka nje ya kizuizi, tumia 'toa'")},
+		{`toa 1`, errorMessage("Mstari 0: 'toa' nje ya kizuizi")},
+		{`fanya f = unda() { toa 1 }; kizuizi { f() }`, errorMessage("Mstari 0: 'toa' nje ya kizuizi")},
+		{`kizuizi { toa x }`, errorMessage("Mstari 0: Neno Halifahamiki: x")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("%s: expected %q, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestDurationBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`mudaKutokaNeno("1h30m")`, 5400000},
+		{`mudaKutokaNeno("500ms")`, 500},
+		{`mudaKutokaNeno("2s")`, 2000},
+		{`mudaKutokaNeno("-1m")`, -60000},
+		{`mudaKutokaNeno("1.5s")`, 1500},
+		{`mudaKwaNeno(5400000)`, "1h30m0s"},
+		{`mudaKwaNeno(500)`, "500ms"},
+		{`mudaKwaNeno(0)`, "0s"},
+		{`mudaKwaNeno(mudaKutokaNeno("2h45m10s"))`, "2h45m10s"},
+		{`mudaKutokaNeno(mudaKwaNeno(90061))`, 90061},
+		{`mudaKutokaNeno("saa moja")`, errorMessage("Mstari 0: Samahani, muda 'saa moja' hauko sahihi, tumia kama \"1h30m\" au \"500ms\"")},
+		{`mudaKutokaNeno("10")`, errorMessage("Mstari 0: Samahani, muda '10' hauko sahihi, tumia kama \"1h30m\" au \"500ms\"")},
+		{`mudaKutokaNeno(10)`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NAMBA")},
+		{`mudaKwaNeno("1s")`, errorMessage("Mstari 0: Samahani, hii function haitumiki na NENO")},
+		{`mudaKwaNeno(9223372036854775807)`, errorMessage("Mstari 0: Samahani, muda 9223372036854775807 ni mkubwa mno")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("%s: expected %q, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestMadirishaBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`madirisha([1, 2, 3, 4], 2)`, "[[1, 2], [2, 3], [3, 4]]"},
+		{`madirisha([1, 2, 3, 4, 5], 3)`, "[[1, 2, 3], [2, 3, 4], [3, 4, 5]]"},
+		{`madirisha([1, 2, 3], 1)`, "[[1], [2], [3]]"},
+		{`madirisha([1, 2, 3], 3)`, "[[1, 2, 3]]"},
+		{`madirisha([1, 2, 3], 4)`, "[]"},
+		{`madirisha([], 1)`, "[]"},
+		{`fanya a = [1, 2, 3]; fanya d = madirisha(a, 2); d[0][0] = 9; a`, "[1, 2, 3]"},
+		{`madirisha([1, 2], 0)`, errorMessage("Mstari 0: Samahani, ukubwa wa dirisha lazima uwe zaidi ya 0, sio 0")},
+		{`madirisha([1, 2], -1)`, errorMessage("Mstari 0: Samahani, ukubwa wa dirisha lazima uwe zaidi ya 0, sio -1")},
+		{`madirisha("abc", 1)`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe ORODHA, sio NENO")},
+		{`madirisha([1], 1.5)`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe NAMBA, sio DESIMALI")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%s: expected %s, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestZoteMbiliAndMojawapo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`zoteMbili(kweli, kweli)`, true},
+		{`zoteMbili(kweli, sikweli)`, false},
+		{`zoteMbili(sikweli, kweli)`, false},
+		{`zoteMbili(sikweli, sikweli)`, false},
+		{`zoteMbili(1, "a")`, true},
+		{`zoteMbili(0, [])`, true},
+		{`zoteMbili(tupu, 1)`, false},
+		{`mojawapo(kweli, sikweli)`, true},
+		{`mojawapo(sikweli, kweli)`, true},
+		{`mojawapo(sikweli, sikweli)`, false},
+		{`mojawapo(tupu, sikweli)`, false},
+		{`mojawapo(tupu, "a")`, true},
+		{`mojawapo(5, tupu)`, true},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testErrorObject(t, testEval(`zoteMbili(kweli)`), "Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 1")
+	testErrorObject(t, testEval(`mojawapo(1, 2, 3)`), "Mstari 0: Samahani, tunahitaji Hoja 2, wewe umeweka 3")
+}
+
+func TestOnyeshaBuiltin(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`onyesha(5)`, "5"},
+		{`onyesha("habari")`, "habari"},
+		{`onyesha([1, "a", {"b": tupu}])`, "[1, a, {b: null}]"},
+		{`onyesha(tupu)`, "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok || str.Value != tt.expected {
+			t.Errorf("%s: expected %q, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+
+	// an error stops a Nuru call before it is made, so errors only reach
+	// onyesha from Go
+	err := testEval(`1 + "a"`)
+	if !isError(err) {
+		t.Fatalf("expected an error, got=%s", err.Inspect())
+	}
+	shown := builtins["onyesha"].Fn(0, err).(*object.String)
+	if strings.Contains(shown.Value, "\x1b[") {
+		t.Errorf("onyesha should not add color codes, got=%q", shown.Value)
+	}
+	if shown.Value != "Kosa: "+err.(*object.Error).Message {
+		t.Errorf("wrong plain error, got=%q", shown.Value)
+	}
+}
+
+func TestTopLevelReturn(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rudisha 42", 42},
+		{"fanya x = 1; rudisha 42; x = 2; x", 42},
+		{"kama (kweli) { rudisha 42 }; 7", 42},
+		{"kwa i ktk [40, 41, 42] { kama (i == 42) { rudisha i } }; 0", 42},
+		{"fanya f = unda() { rudisha 1 }; f(); 42", 42},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		evaluated := Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		if _, ok := evaluated.(*object.ReturnValue); ok {
+			t.Errorf("%s: the program result should be unwrapped, got=%T", tt.input, evaluated)
+			continue
+		}
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+
+	env := object.NewEnvironment()
+	Eval(parser.New(lexer.New("fanya x = 1; rudisha 42; x = 2")).ParseProgram(), env)
+	if x, _ := env.Get("x"); x.(*object.Integer).Value != 1 {
+		t.Errorf("statements after a top level rudisha should not run, x=%s", x.Inspect())
+	}
+}
+
+func TestUkubwaWaKumbukumbu(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`ukubwaWaKumbukumbu(5)`, 8},
+		{`ukubwaWaKumbukumbu(2.5)`, 8},
+		{`ukubwaWaKumbukumbu(kweli)`, 1},
+		{`ukubwaWaKumbukumbu(tupu)`, 1},
+		{`ukubwaWaKumbukumbu("")`, 16},
+		{`ukubwaWaKumbukumbu("habari")`, 22},
+		{`ukubwaWaKumbukumbu([])`, 24},
+		{`ukubwaWaKumbukumbu([1, 2])`, 24 + 2*(8+8)},
+		{`ukubwaWaKumbukumbu({"a": 1})`, 24 + 16 + 17 + 8},
+		{`ukubwaWaKumbukumbu(kwaSeti([1, 2]))`, 24 + 2*(8+8)},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	relative := []struct {
+		smaller string
+		larger  string
+	}{
+		{`"a"`, `"habari za asubuhi"`},
+		{`[1, 2, 3]`, `mfululizo(0, 1000)`},
+		{`[1, 2, 3]`, `[[1, 2, 3], [4, 5, 6]]`},
+		{`{"a": 1}`, `{"a": 1, "b": {"c": [1, 2, 3]}}`},
+		{`mfululizo(0, 10)`, `[mfululizo(0, 10), mfululizo(0, 10)]`},
+	}
+
+	for _, tt := range relative {
+		small := testEval("ukubwaWaKumbukumbu(" + tt.smaller + ")").(*object.Integer)
+		large := testEval("ukubwaWaKumbukumbu(" + tt.larger + ")").(*object.Integer)
+		if small.Value >= large.Value {
+			t.Errorf("%s (%d) should be smaller than %s (%d)", tt.smaller, small.Value, tt.larger, large.Value)
+		}
+	}
+
+	// an array that holds itself is only counted once
+	self := &object.Array{}
+	self.Elements = []object.Object{self}
+	if size := estimateSize(self, map[object.Object]bool{}); size != 24+8+8 {
+		t.Errorf("wrong size for an array inside itself, got=%d", size)
+	}
+}
+
+func TestUkcdAndUkuu(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`ukcd(8, 15)`, 1},
+		{`ukcd(12, 18)`, 6},
+		{`ukcd(-12, 18)`, 6},
+		{`ukcd(12, -18)`, 6},
+		{`ukcd(0, 5)`, 5},
+		{`ukcd(5, 0)`, 5},
+		{`ukcd(0, 0)`, 0},
+		{`ukcd(24, 36, 60)`, 12},
+		{`ukuu(8, 15)`, 120},
+		{`ukuu(4, 6)`, 12},
+		{`ukuu(-4, 6)`, 12},
+		{`ukuu(0, 6)`, 0},
+		{`ukuu(0, 0)`, 0},
+		{`ukuu(2, 3, 4)`, 12},
+		{`ukuu(9223372036854775807, 2)`, errorMessage("Mstari 0: Samahani, jibu la ukuu ni kubwa mno")},
+		{`ukcd(-9223372036854775807 - 1, 0)`, errorMessage("Mstari 0: Samahani, jibu la ukcd ni kubwa mno")},
+		{`ukcd(4)`, errorMessage("Mstari 0: Samahani, ukcd inahitaji angalau Hoja 2, wewe umeweka 1")},
+		{`ukuu(4, 2.5)`, errorMessage("Mstari 0: Samahani, ukuu inatumika na NAMBA tu, sio DESIMALI")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
+
+func TestDesimaliKwaNeno(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`desimaliKwaNeno(3.14159, 2)`, "3.14"},
+		{`desimaliKwaNeno(2.675, 2)`, "2.68"},
+		{`desimaliKwaNeno(1.005, 2)`, "1.01"},
+		{`desimaliKwaNeno(1.004, 2)`, "1.00"},
+		{`desimaliKwaNeno(9.995, 2)`, "10.00"},
+		{`desimaliKwaNeno(0.5, 0)`, "1"},
+		{`desimaliKwaNeno(2.5, 0)`, "3"},
+		{`desimaliKwaNeno(2.4, 0)`, "2"},
+		{`desimaliKwaNeno(-2.5, 0)`, "-3"},
+		{`desimaliKwaNeno(-1.234, 1)`, "-1.2"},
+		{`desimaliKwaNeno(-0.001, 2)`, "0.00"},
+		{`desimaliKwaNeno(1.5, 3)`, "1.500"},
+		{`desimaliKwaNeno(7, 2)`, "7.00"},
+		{`desimaliKwaNeno(7, 0)`, "7"},
+		{`desimaliKwaNeno(0.1 + 0.2, 2)`, "0.30"},
+		{`desimaliKwaNeno(1.5, 101)`, errorMessage("Mstari 0: Samahani, idadi ya tarakimu haiwezi kuzidi 100, sio 101")},
+		{`desimaliKwaNeno(1.5, 9223372036854775807)`, errorMessage("Mstari 0: Samahani, idadi ya tarakimu haiwezi kuzidi 100, sio 9223372036854775807")},
+		{`desimaliKwaNeno(1.5, -1)`, errorMessage("Mstari 0: Samahani, idadi ya tarakimu haiwezi kuwa hasi: -1")},
+		{`desimaliKwaNeno("1.5", 1)`, errorMessage("Mstari 0: Samahani, hoja ya kwanza lazima iwe namba, sio NENO")},
+		{`desimaliKwaNeno(1.5, 1.0)`, errorMessage("Mstari 0: Samahani, hoja ya pili lazima iwe NAMBA, sio DESIMALI")},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("%s: expected %q, got=%s", tt.input, expected, evaluated.Inspect())
+			}
+		case errorMessage:
+			testErrorObject(t, evaluated, string(expected))
+		}
+	}
+}
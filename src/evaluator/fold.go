@@ -0,0 +1,181 @@
+package evaluator
+
+import (
+	"strconv"
+
+	"github.com/AvicennaJr/Nuru/ast"
+	"github.com/AvicennaJr/Nuru/object"
+	"github.com/AvicennaJr/Nuru/token"
+)
+
+// arithmeticOperators are the infix operators Fold works out ahead of time
+var arithmeticOperators = map[string]bool{
+	"+": true, "-": true, "*": true, "/": true, "%": true, "**": true, "~/": true,
+}
+
+// Fold replaces arithmetic on number literals, eg 2 * 60 * 60, with the
+// literal it works out to, so a loop does not redo it on every pass. It
+// runs between parsing and Eval. Only literals are folded: anything that
+// involves a variable or a call is left alone, and so is arithmetic that
+// fails, so the error is still reported when the program runs.
+func Fold(program *ast.Program) *ast.Program {
+	foldStatements(program.Statements)
+	return program
+}
+
+func foldStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		foldStatement(stmt)
+	}
+}
+
+func foldStatement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		stmt.Value = foldExpression(stmt.Value)
+	case *ast.ReturnStatement:
+		stmt.ReturnValue = foldExpression(stmt.ReturnValue)
+	case *ast.YieldStatement:
+		stmt.Value = foldExpression(stmt.Value)
+	case *ast.ExpressionStatement:
+		stmt.Expression = foldExpression(stmt.Expression)
+	case *ast.BlockStatement:
+		foldBlock(stmt)
+	}
+}
+
+func foldBlock(block *ast.BlockStatement) {
+	if block != nil {
+		foldStatements(block.Statements)
+	}
+}
+
+func foldExpressions(exps []ast.Expression) {
+	for i, exp := range exps {
+		exps[i] = foldExpression(exp)
+	}
+}
+
+// foldExpression folds everything below exp and returns what should take
+// its place, which is exp itself unless it became a literal.
+func foldExpression(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		exp.Right = foldExpression(exp.Right)
+		right, ok := numberLiteral(exp.Right)
+		if !ok || exp.Operator != "-" {
+			return exp
+		}
+		return foldedLiteral(exp, exp.Token, func() object.Object {
+			return evalPrefixExpression(exp.Operator, right, exp.Token.Line)
+		})
+
+	case *ast.InfixExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Right = foldExpression(exp.Right)
+		left, leftOk := numberLiteral(exp.Left)
+		right, rightOk := numberLiteral(exp.Right)
+		if !leftOk || !rightOk || !arithmeticOperators[exp.Operator] {
+			return exp
+		}
+		return foldedLiteral(exp, exp.Token, func() object.Object {
+			return evalInfixExpression(exp.Operator, left, right, exp.Token.Line)
+		})
+
+	case *ast.IfExpression:
+		exp.Condition = foldExpression(exp.Condition)
+		foldBlock(exp.Consequence)
+		foldBlock(exp.Alternative)
+	case *ast.FunctionLiteral:
+		foldBlock(exp.Body)
+	case *ast.BlockExpression:
+		foldBlock(exp.Block)
+	case *ast.CallExpression:
+		exp.Function = foldExpression(exp.Function)
+		foldExpressions(exp.Arguments)
+		for _, named := range exp.Named {
+			named.Value = foldExpression(named.Value)
+		}
+	case *ast.ArrayLiteral:
+		foldExpressions(exp.Elements)
+	case *ast.DictLiteral:
+		pairs := make(map[ast.Expression]ast.Expression, len(exp.Pairs))
+		for i, key := range exp.Keys {
+			value := exp.Pairs[key]
+			exp.Keys[i] = foldExpression(key)
+			pairs[exp.Keys[i]] = foldExpression(value)
+		}
+		exp.Pairs = pairs
+	case *ast.IndexExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Index = foldExpression(exp.Index)
+	case *ast.SliceExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Start = foldExpression(exp.Start)
+		exp.End = foldExpression(exp.End)
+		exp.Step = foldExpression(exp.Step)
+	case *ast.Comprehension:
+		exp.Key = foldExpression(exp.Key)
+		exp.Value = foldExpression(exp.Value)
+		exp.Iterable = foldExpression(exp.Iterable)
+		exp.Condition = foldExpression(exp.Condition)
+	case *ast.AssignmentExpression:
+		exp.Left = foldExpression(exp.Left)
+		exp.Value = foldExpression(exp.Value)
+	case *ast.WhileExpression:
+		exp.Condition = foldExpression(exp.Condition)
+		foldBlock(exp.Consequence)
+	case *ast.For:
+		exp.StarterValue = foldExpression(exp.StarterValue)
+		exp.Closer = foldExpression(exp.Closer)
+		exp.Condition = foldExpression(exp.Condition)
+		foldBlock(exp.Block)
+	case *ast.ForIn:
+		exp.Iterable = foldExpression(exp.Iterable)
+		foldBlock(exp.Block)
+	case *ast.SwitchExpression:
+		exp.Value = foldExpression(exp.Value)
+		for _, choice := range exp.Choices {
+			foldExpressions(choice.Expr)
+			choice.Guard = foldExpression(choice.Guard)
+			foldBlock(choice.Block)
+		}
+	case *ast.PropertyExpression:
+		exp.Object = foldExpression(exp.Object)
+	}
+	return exp
+}
+
+// numberLiteral gives the value of an integer or float literal
+func numberLiteral(exp ast.Expression) (object.Object, bool) {
+	switch exp := exp.(type) {
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: exp.Value}, true
+	case *ast.FloatLiteral:
+		return &object.Float{Value: exp.Value}, true
+	default:
+		return nil, false
+	}
+}
+
+// foldedLiteral runs compute and turns its result into a literal standing
+// in for exp, on the line of exp's operator tok. If compute fails, or even
+// panics, exp is kept so that Eval reports the problem as it always has.
+func foldedLiteral(exp ast.Expression, tok token.Token, compute func() object.Object) (folded ast.Expression) {
+	defer func() {
+		if recover() != nil {
+			folded = exp
+		}
+	}()
+
+	switch result := compute().(type) {
+	case *object.Integer:
+		tok.Type, tok.Literal = token.INT, strconv.FormatInt(result.Value, 10)
+		return &ast.IntegerLiteral{Token: tok, Value: result.Value}
+	case *object.Float:
+		tok.Type, tok.Literal = token.FLOAT, strconv.FormatFloat(result.Value, 'f', -1, 64)
+		return &ast.FloatLiteral{Token: tok, Value: result.Value}
+	default:
+		return exp
+	}
+}
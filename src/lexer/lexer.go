@@ -1,6 +1,10 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
 	"github.com/AvicennaJr/Nuru/token"
 )
 
@@ -10,6 +14,7 @@ type Lexer struct {
 	readPosition int
 	ch           byte
 	line         int
+	errors       []string
 }
 
 func New(input string) *Lexer {
@@ -102,6 +107,19 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.SLASH, l.line, l.ch)
 		}
+	case '~':
+		if l.peekChar() == '/' {
+			ch := l.ch
+			l.readChar()
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.FLOOR_DIV_ASSIGN, Line: l.line, Literal: "~/="}
+			} else {
+				tok = token.Token{Type: token.FLOOR_DIV, Line: l.line, Literal: string(ch) + string(l.ch)}
+			}
+		} else {
+			tok = newToken(token.ILLEGAL, l.line, l.ch)
+		}
 	case '*':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -110,7 +128,12 @@ func (l *Lexer) NextToken() token.Token {
 		} else if l.peekChar() == '*' {
 			ch := l.ch
 			l.readChar()
-			tok = token.Token{Type: token.POW, Literal: string(ch) + string(l.ch), Line: l.line}
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.POW_ASSIGN, Literal: string(ch) + string(ch) + string(l.ch), Line: l.line}
+			} else {
+				tok = token.Token{Type: token.POW, Literal: string(ch) + string(l.ch), Line: l.line}
+			}
 		} else {
 			tok = newToken(token.ASTERISK, l.line, l.ch)
 		}
@@ -132,27 +155,48 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case '"':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		tok.Literal = l.readString('"')
 		tok.Line = l.line
 	case '\'':
-		tok = token.Token{Type: token.STRING, Literal: l.readSingleQuoteString(), Line: l.line}
+		tok = token.Token{Type: token.STRING, Literal: l.readString('\''), Line: l.line}
 	case '[':
 		tok = newToken(token.LBRACKET, l.line, l.ch)
 	case ']':
 		tok = newToken(token.RBRACKET, l.line, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.line, l.ch)
+	case '.':
+		tok = newToken(token.DOT, l.line, l.ch)
+	case '?':
+		if l.peekChar() == '.' || l.peekChar() == '[' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{Type: token.TokenType(literal), Literal: literal, Line: l.line}
+		} else {
+			tok = newToken(token.ILLEGAL, l.line, l.ch)
+		}
 	case '&':
 		if l.peekChar() == '&' {
 			ch := l.ch
 			l.readChar()
-			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch), Line: l.line}
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.AND_ASSIGN, Literal: string(ch) + string(ch) + string(l.ch), Line: l.line}
+			} else {
+				tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch), Line: l.line}
+			}
 		}
 	case '|':
 		if l.peekChar() == '|' {
 			ch := l.ch
 			l.readChar()
-			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch), Line: l.line}
+			if l.peekChar() == '=' {
+				l.readChar()
+				tok = token.Token{Type: token.OR_ASSIGN, Literal: string(ch) + string(ch) + string(l.ch), Line: l.line}
+			} else {
+				tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch), Line: l.line}
+			}
 		}
 	case '%':
 		if l.peekChar() == '=' {
@@ -171,6 +215,10 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
 			tok.Line = l.line
+			if tok.Literal == "si" && l.skipKeyword("ktk") {
+				tok.Type = token.NOT_IN
+				tok.Literal = "si ktk"
+			}
 			return tok
 		} else if isDigit(l.ch) {
 			tok = l.readDecimal()
@@ -197,6 +245,26 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[position:l.position]
 }
 
+// skipKeyword moves past word if it comes next on the same line, so "si"
+// stays a normal identifier unless it is followed by ktk.
+func (l *Lexer) skipKeyword(word string) bool {
+	pos := l.position
+	for pos < len(l.input) && (l.input[pos] == ' ' || l.input[pos] == '\t') {
+		pos++
+	}
+	end := pos + len(word)
+	if end > len(l.input) || l.input[pos:end] != word {
+		return false
+	}
+	if end < len(l.input) && (isLetter(l.input[end]) || isDigit(l.input[end])) {
+		return false
+	}
+	for l.position < end {
+		l.readChar()
+	}
+	return true
+}
+
 func isLetter(ch byte) bool {
 	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
 }
@@ -266,62 +334,66 @@ func (l *Lexer) skipMultiLineComment() {
 
 }
 
-func (l *Lexer) readString() string {
-	var str string
+func (l *Lexer) readString(quote byte) string {
+	var str []byte
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == quote || l.ch == 0 {
 			break
-		} else if l.ch == '\\' {
-			switch l.peekChar() {
-			case 'n':
-				l.readChar()
-				l.ch = '\n'
-			case 'r':
-				l.readChar()
-				l.ch = '\r'
-			case 't':
-				l.readChar()
-				l.ch = '\t'
-			case '"':
-				l.readChar()
-				l.ch = '"'
-			case '\\':
-				l.readChar()
-				l.ch = '\\'
-			}
 		}
-		str += string(l.ch)
+		if l.ch == '\\' {
+			l.readChar()
+			str = l.readEscape(str)
+			continue
+		}
+		str = append(str, l.ch)
 	}
-	return str
+	return string(str)
 }
 
-func (l *Lexer) readSingleQuoteString() string {
-	var str string
-	for {
-		l.readChar()
-		if l.ch == '\'' || l.ch == 0 {
-			break
-		} else if l.ch == '\\' {
-			switch l.peekChar() {
-			case 'n':
-				l.readChar()
-				l.ch = '\n'
-			case 'r':
-				l.readChar()
-				l.ch = '\r'
-			case 't':
-				l.readChar()
-				l.ch = '\t'
-			case '"':
-				l.readChar()
-				l.ch = '"'
-			case '\\':
-				l.readChar()
-				l.ch = '\\'
+// readEscape decodes the escape sequence whose first character (after the
+// backslash) is l.ch and appends the result to str. Unknown escapes are
+// recorded as errors and kept as they were written.
+func (l *Lexer) readEscape(str []byte) []byte {
+	switch l.ch {
+	case 'n':
+		return append(str, '\n')
+	case 'r':
+		return append(str, '\r')
+	case 't':
+		return append(str, '\t')
+	case 'b':
+		return append(str, '\b')
+	case 'f':
+		return append(str, '\f')
+	case 'v':
+		return append(str, '\v')
+	case '0':
+		return append(str, 0)
+	case '\\', '"', '\'':
+		return append(str, l.ch)
+	case 'u':
+		end := l.readPosition + 4
+		if end <= len(l.input) {
+			if r, err := strconv.ParseUint(l.input[l.readPosition:end], 16, 32); err == nil {
+				for l.readPosition < end {
+					l.readChar()
+				}
+				return utf8.AppendRune(str, rune(r))
 			}
 		}
-		str += string(l.ch)
+		l.errors = append(l.errors, fmt.Sprintf("Mstari %d: \\u lazima ifuatwe na tarakimu 4 za hex", l.line))
+		return append(str, '\\', 'u')
+	case 0:
+		l.errors = append(l.errors, fmt.Sprintf("Mstari %d: Neno limeishia na \\", l.line))
+		return append(str, '\\')
+	default:
+		l.errors = append(l.errors, fmt.Sprintf("Mstari %d: Alama ya kutoroka haijulikani: \\%c", l.line, l.ch))
+		return append(str, '\\', l.ch)
 	}
-	return str
+}
+
+// Errors returns the problems found while reading string literals
+func (l *Lexer) Errors() []string {
+	return l.errors
 }
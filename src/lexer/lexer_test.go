@@ -151,3 +151,201 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNonASCIIStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"café"`, "café"},
+		{`'ÉLÈVE'`, "ÉLÈVE"},
+		{`"habari ✓"`, "habari ✓"},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != token.STRING {
+			t.Fatalf("%s: expected STRING, got=%q", tt.input, tok.Type)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("%s: expected=%q, got=%q", tt.input, tt.expected, tok.Literal)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb"`, "a\tb"},
+		{`"a\rb"`, "a\rb"},
+		{`"a\bb\fc\vd"`, "a\bb\fc\vd"},
+		{`"a\0b"`, "a\x00b"},
+		{`"sema \"habari\""`, `sema "habari"`},
+		{`'sema \'habari\''`, `sema 'habari'`},
+		{`'sema \"habari\"'`, `sema "habari"`},
+		{`"C:\\nuru"`, `C:\nuru`},
+		{`"caf\u00e9"`, "café"},
+		{`"\u03A3\u03bf\u03C6"`, "Σοφ"},
+		{`"jambo 🌍"`, "jambo 🌍"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong. expected=%q, got=%q", token.STRING, tok.Type)
+		}
+		if tok.Literal != tt.expected {
+			t.Errorf("literal wrong. expected=%q, got=%q", tt.expected, tok.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Errorf("unexpected lexer errors for %s: %v", tt.input, l.Errors())
+		}
+	}
+}
+
+func TestInvalidStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\qb"`, `Mstari 0: Alama ya kutoroka haijulikani: \q`},
+		{`"a\u12"`, `Mstari 0: \u lazima ifuatwe na tarakimu 4 za hex`},
+		{`"a\uzzzz"`, `Mstari 0: \u lazima ifuatwe na tarakimu 4 za hex`},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		l.NextToken()
+
+		if len(l.Errors()) != 1 {
+			t.Fatalf("expected 1 lexer error for %s, got=%v", tt.input, l.Errors())
+		}
+		if l.Errors()[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, l.Errors()[0])
+		}
+	}
+}
+
+func TestLogicalAssignTokens(t *testing.T) {
+	l := New(`a &&= b || c ||= d && e`)
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "a"},
+		{Type: token.AND_ASSIGN, Literal: "&&="},
+		{Type: token.IDENT, Literal: "b"},
+		{Type: token.OR, Literal: "||"},
+		{Type: token.IDENT, Literal: "c"},
+		{Type: token.OR_ASSIGN, Literal: "||="},
+		{Type: token.IDENT, Literal: "d"},
+		{Type: token.AND, Literal: "&&"},
+		{Type: token.IDENT, Literal: "e"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Fatalf("tests[%d] - expected=%q %q, got=%q %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestFloorDivisionToken(t *testing.T) {
+	l := New(`7 ~/ 2 // maelezo
+	~`)
+	expected := []token.Token{
+		{Type: token.INT, Literal: "7"},
+		{Type: token.FLOOR_DIV, Literal: "~/"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.ILLEGAL, Literal: "~"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Fatalf("tests[%d] - expected=%q %q, got=%q %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNotInToken(t *testing.T) {
+	l := New(`a si ktk b; si = sikweli; si	ktk; si ktkx`)
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "a"},
+		{Type: token.NOT_IN, Literal: "si ktk"},
+		{Type: token.IDENT, Literal: "b"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENT, Literal: "si"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.FALSE, Literal: "sikweli"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.NOT_IN, Literal: "si ktk"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENT, Literal: "si"},
+		{Type: token.IDENT, Literal: "ktkx"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Fatalf("tests[%d] - expected=%q %q, got=%q %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestPowerAndFloorAssignTokens(t *testing.T) {
+	l := New(`x **= 2 ** 3; y ~/= 3 ~/ 2`)
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.POW_ASSIGN, Literal: "**="},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.POW, Literal: "**"},
+		{Type: token.INT, Literal: "3"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.IDENT, Literal: "y"},
+		{Type: token.FLOOR_DIV_ASSIGN, Literal: "~/="},
+		{Type: token.INT, Literal: "3"},
+		{Type: token.FLOOR_DIV, Literal: "~/"},
+		{Type: token.INT, Literal: "2"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Fatalf("tests[%d] - expected=%q %q, got=%q %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestOptionalAccessTokens(t *testing.T) {
+	l := New(`a?.b?[0].c[1] ?`)
+	expected := []token.Token{
+		{Type: token.IDENT, Literal: "a"},
+		{Type: token.OPTIONAL_DOT, Literal: "?."},
+		{Type: token.IDENT, Literal: "b"},
+		{Type: token.OPTIONAL_LBRACKET, Literal: "?["},
+		{Type: token.INT, Literal: "0"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.DOT, Literal: "."},
+		{Type: token.IDENT, Literal: "c"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.INT, Literal: "1"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.ILLEGAL, Literal: "?"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != want.Type || tok.Literal != want.Literal {
+			t.Fatalf("tests[%d] - expected=%q %q, got=%q %q", i, want.Type, want.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
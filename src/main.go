@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/AvicennaJr/Nuru/object"
 	"github.com/AvicennaJr/Nuru/repl"
 )
 
@@ -22,6 +23,12 @@ const (
 func main() {
 
 	args := os.Args
+
+	// errors are only colored on a terminal, unless NO_COLOR is set
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || !isTerminal(os.Stdout) {
+		object.SetColor(false)
+	}
+
 	coloredLogo := fmt.Sprintf("\x1b[%dm%s\x1b[0m", 36, LOGO)
 
 	if len(args) < 2 {
@@ -65,3 +72,8 @@ func main() {
 		os.Exit(0)
 	}
 }
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
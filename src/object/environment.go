@@ -3,21 +3,45 @@ package object
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.builtins = outer.builtins
 	return env
 }
 
+// NewSizedEnclosedEnvironment is like NewEnclosedEnvironment, but made for
+// a function call that starts with size variables. Small environments
+// keep their variables in a slice, which costs far less to make than a
+// map, so deep recursion allocates much less.
+func NewSizedEnclosedEnvironment(outer *Environment, size int) *Environment {
+	if size > maxSmallEnvironment {
+		return NewEnclosedEnvironment(outer)
+	}
+	return &Environment{small: make([]binding, 0, size), outer: outer, builtins: outer.builtins}
+}
+
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
 	return &Environment{store: s, outer: nil}
 }
 
+// maxSmallEnvironment is how many variables an environment keeps in its
+// slice before moving them into a map
+const maxSmallEnvironment = 8
+
+// binding is one variable of a small environment
+type binding struct {
+	name  string
+	value Object
+}
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]Object // nil while the variables fit in small
+	small    []binding
+	outer    *Environment
+	builtins map[string]*Builtin // copied from outer when enclosed
 }
 
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	obj, ok := e.lookup(name)
 
 	if !ok && e.outer != nil {
 		obj, ok = e.outer.Get(name)
@@ -25,7 +49,56 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// lookup finds name in this environment only
+func (e *Environment) lookup(name string) (Object, bool) {
+	if e.store != nil {
+		obj, ok := e.store[name]
+		return obj, ok
+	}
+	for _, b := range e.small {
+		if b.name == name {
+			return b.value, true
+		}
+	}
+	return nil, false
+}
+
 func (e *Environment) Set(name string, val Object) Object {
+	if e.store != nil {
+		e.store[name] = val
+		return val
+	}
+
+	for i := range e.small {
+		if e.small[i].name == name {
+			e.small[i].value = val
+			return val
+		}
+	}
+	if len(e.small) < maxSmallEnvironment {
+		e.small = append(e.small, binding{name: name, value: val})
+		return val
+	}
+
+	e.store = make(map[string]Object, len(e.small)+1)
+	for _, b := range e.small {
+		e.store[b.name] = b.value
+	}
+	e.small = nil
 	e.store[name] = val
 	return val
 }
+
+// SetBuiltins limits the builtins visible from this environment, and
+// every environment enclosed by it afterwards, to the ones in b
+func (e *Environment) SetBuiltins(b map[string]*Builtin) {
+	e.builtins = b
+}
+
+// Builtins returns the builtins set with SetBuiltins on this environment
+// or an outer one, or nil if all builtins are allowed. Enclosed
+// environments take the set from outer when they are made, so this does
+// not have to walk up through every call of a deep recursion.
+func (e *Environment) Builtins() map[string]*Builtin {
+	return e.builtins
+}
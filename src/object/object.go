@@ -1,12 +1,15 @@
 package object
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/AvicennaJr/Nuru/ast"
 )
@@ -27,6 +30,10 @@ const (
 	DICT_OBJ         = "KAMUSI"
 	CONTINUE_OBJ     = "ENDELEA"
 	BREAK_OBJ        = "VUNJA"
+	YIELD_OBJ        = "TOA"
+	LINES_OBJ        = "MISTARI"
+	BUILDER_OBJ      = "MJENGO"
+	SET_OBJ          = "SETI"
 )
 
 type Object interface {
@@ -73,17 +80,50 @@ type ReturnValue struct {
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
+// colored is whether Colorize adds ANSI color codes
+var colored = true
+
+// SetColor turns the ANSI colors used for errors on or off. They only
+// make sense on a terminal, and garble output sent to a file or a log.
+func SetColor(on bool) {
+	colored = on
+}
+
+// Colorize wraps str in the ANSI color colorCode, or returns it as it is
+// when colors are turned off
+func Colorize(str string, colorCode int) string {
+	if !colored {
+		return str
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", colorCode, str)
+}
+
 type Error struct {
-	Message string
+	Message string   // the plain message, Inspect adds the color
+	Trace   []string // the function calls that led to the error, innermost first
 }
 
 func (e *Error) Inspect() string {
-	msg := fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, "Kosa: ")
-	return msg + e.Message
+	return Colorize("Kosa: ", 31) + Colorize(e.Message, 31) + e.trace()
+}
+
+// Plain is Inspect without any color, whether or not colors are turned on,
+// for writing errors to logs and files
+func (e *Error) Plain() string {
+	return "Kosa: " + e.Message + e.trace()
+}
+
+func (e *Error) trace() string {
+	var out bytes.Buffer
+	for _, frame := range e.Trace {
+		out.WriteString("\n\t" + frame)
+	}
+	return out.String()
 }
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
 type Function struct {
+	Name       string // the name it was first bound to, empty for anonymous functions
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -101,33 +141,40 @@ func (f *Function) Inspect() string {
 	out.WriteString("unda")
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
-	out.WriteString(") {\n")
-	out.WriteString(f.Body.String())
-	out.WriteString("\n}")
+	out.WriteString(") {...}")
 
 	return out.String()
 }
 
 type String struct {
 	Value  string
-	offset int
+	offset int // byte position of the next character
+	index  int // how many characters have been read
 }
 
 func (s *String) Inspect() string  { return s.Value }
 func (s *String) Type() ObjectType { return STRING_OBJ }
+
+// Next steps through the string one character at a time, so a multi-byte
+// character like "é" comes out whole, numbered by its position in the
+// string rather than its byte offset.
 func (s *String) Next() (Object, Object) {
-	offset := s.offset
-	if len(s.Value) > offset {
-		s.offset = offset + 1
-		return &Integer{Value: int64(offset)}, &String{Value: string(s.Value[offset])}
+	if s.offset >= len(s.Value) {
+		return nil, nil
 	}
-	return nil, nil
+	_, size := utf8.DecodeRuneInString(s.Value[s.offset:])
+	char := s.Value[s.offset : s.offset+size]
+	idx := s.index
+	s.offset += size
+	s.index++
+	return &Integer{Value: int64(idx)}, &String{Value: char}
 }
 func (s *String) Reset() {
 	s.offset = 0
+	s.index = 0
 }
 
-type BuiltinFunction func(args ...Object) Object
+type BuiltinFunction func(line int, args ...Object) Object
 
 type Builtin struct {
 	Fn BuiltinFunction
@@ -211,7 +258,9 @@ type DictPair struct {
 
 type Dict struct {
 	Pairs  map[HashKey]DictPair
+	order  []HashKey
 	offset int
+	keys   []HashKey // the keys being looped over, taken when a loop starts
 }
 
 func (d *Dict) Type() ObjectType { return DICT_OBJ }
@@ -220,7 +269,8 @@ func (d *Dict) Inspect() string {
 
 	pairs := []string{}
 
-	for _, pair := range d.Pairs {
+	for _, key := range d.Keys() {
+		pair := d.Pairs[key]
 		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key.Inspect(), pair.Value.Inspect()))
 	}
 
@@ -231,46 +281,254 @@ func (d *Dict) Inspect() string {
 	return out.String()
 }
 
-func (d *Dict) Next() (Object, Object) {
-	idx := 0
-	dict := make(map[string]DictPair)
-	var keys []string
-	for _, v := range d.Pairs {
-		dict[v.Key.Inspect()] = v
-		keys = append(keys, v.Key.Inspect())
+// Set adds or updates a pair, remembering the order in which keys were first added
+func (d *Dict) Set(key HashKey, pair DictPair) {
+	if d.Pairs == nil {
+		d.Pairs = make(map[HashKey]DictPair)
+	}
+	if _, ok := d.Pairs[key]; !ok {
+		d.order = append(d.order, key)
 	}
+	d.Pairs[key] = pair
+}
 
-	sort.Strings(keys)
+// Keys returns the keys in insertion order. Pairs written straight into
+// the map without Set have no known order, so they come last sorted by key.
+func (d *Dict) Keys() []HashKey {
+	keys := make([]HashKey, 0, len(d.Pairs))
+	seen := make(map[HashKey]bool, len(d.order))
+	for _, key := range d.order {
+		if _, ok := d.Pairs[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
 
-	for _, k := range keys {
-		if d.offset == idx {
-			d.offset += 1
-			return dict[k].Key, dict[k].Value
+	if len(keys) < len(d.Pairs) {
+		var rest []HashKey
+		for key := range d.Pairs {
+			if !seen[key] {
+				rest = append(rest, key)
+			}
 		}
-		idx += 1
+		sort.Slice(rest, func(i, j int) bool {
+			return d.Pairs[rest[i]].Key.Inspect() < d.Pairs[rest[j]].Key.Inspect()
+		})
+		keys = append(keys, rest...)
+	}
+
+	return keys
+}
+
+// Next gives the pairs in the order of Keys. The keys are worked out once
+// when the loop starts, so pairs added during the loop are not visited.
+func (d *Dict) Next() (Object, Object) {
+	if d.offset == 0 {
+		d.keys = d.Keys()
+	}
+	if d.offset < len(d.keys) {
+		pair := d.Pairs[d.keys[d.offset]]
+		d.offset += 1
+		return pair.Key, pair.Value
 	}
 	return nil, nil
 }
 
 func (d *Dict) Reset() {
 	d.offset = 0
+	d.keys = nil
 }
 
 type Hashable interface {
 	HashKey() HashKey
 }
 
-type Continue struct{}
+// Set holds each hashable value once, remembering the order in which
+// values were first added.
+type Set struct {
+	members map[HashKey]Object
+	order   []HashKey
+	offset  int
+}
+
+func (s *Set) Type() ObjectType { return SET_OBJ }
+func (s *Set) Inspect() string {
+	elements := []string{}
+	for _, elem := range s.Elements() {
+		elements = append(elements, elem.Inspect())
+	}
+	return "seti{" + strings.Join(elements, ", ") + "}"
+}
+
+// Add puts obj in the set, reporting false if it is already there
+func (s *Set) Add(obj Hashable) bool {
+	if s.members == nil {
+		s.members = make(map[HashKey]Object)
+	}
+	key := obj.HashKey()
+	if _, ok := s.members[key]; ok {
+		return false
+	}
+	s.members[key] = obj.(Object)
+	s.order = append(s.order, key)
+	return true
+}
+
+func (s *Set) Has(obj Hashable) bool {
+	_, ok := s.members[obj.HashKey()]
+	return ok
+}
+
+func (s *Set) Len() int { return len(s.order) }
+
+// Elements returns the members in insertion order
+func (s *Set) Elements() []Object {
+	elements := make([]Object, len(s.order))
+	for i, key := range s.order {
+		elements[i] = s.members[key]
+	}
+	return elements
+}
+
+func (s *Set) Next() (Object, Object) {
+	if s.offset < len(s.order) {
+		idx := s.offset
+		s.offset++
+		return &Integer{Value: int64(idx)}, s.members[s.order[idx]]
+	}
+	return nil, nil
+}
+
+func (s *Set) Reset() {
+	s.offset = 0
+}
+
+type Continue struct {
+	Line int
+}
 
 func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
 func (c *Continue) Inspect() string  { return "continue" }
 
-type Break struct{}
+type Break struct {
+	Line int
+}
 
 func (b *Break) Type() ObjectType { return BREAK_OBJ }
 func (b *Break) Inspect() string  { return "break" }
 
-// Iterable interface for dicts, strings and arrays
+// Yield carries the value of a toa out to the kizuizi around it
+type Yield struct {
+	Value Object
+	Line  int
+}
+
+func (y *Yield) Type() ObjectType { return YIELD_OBJ }
+func (y *Yield) Inspect() string  { return y.Value.Inspect() }
+
+// Lines reads a file one line at a time as it is iterated, so only the
+// current line is held in memory. The file is closed once the last line
+// is read or when the loop is Reset, eg after a break.
+type Lines struct {
+	Path    string
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+	done    bool
+}
+
+func OpenLines(path string) (*Lines, error) {
+	l := &Lines{Path: path}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Lines) open() error {
+	file, err := os.Open(l.Path)
+	if err != nil {
+		return err
+	}
+	l.file = file
+	l.scanner = bufio.NewScanner(file)
+	l.scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	l.line = 0
+	return nil
+}
+
+func (l *Lines) close() {
+	if l.file != nil {
+		l.file.Close()
+	}
+	l.file = nil
+	l.scanner = nil
+}
+
+func (l *Lines) Type() ObjectType { return LINES_OBJ }
+func (l *Lines) Inspect() string  { return fmt.Sprintf("mistari(%s)", l.Path) }
+func (l *Lines) Next() (Object, Object) {
+	if l.done {
+		return nil, nil
+	}
+	if l.file == nil && l.open() != nil {
+		l.done = true
+		return nil, nil
+	}
+	if !l.scanner.Scan() {
+		l.close()
+		l.done = true
+		return nil, nil
+	}
+	idx := l.line
+	l.line++
+	return &Integer{Value: int64(idx)}, &String{Value: l.scanner.Text()}
+}
+
+func (l *Lines) Reset() {
+	l.close()
+	l.done = false
+}
+
+// Builder collects text, or array elements when Array is set, so a result
+// can be built up piece by piece without copying it on every step the way
+// s = s + x does.
+type Builder struct {
+	Array    bool
+	text     strings.Builder
+	elements []Object
+}
+
+func (b *Builder) Type() ObjectType { return BUILDER_OBJ }
+func (b *Builder) Inspect() string {
+	if b.Array {
+		return fmt.Sprintf("mjengo(orodha ya %d)", len(b.elements))
+	}
+	return fmt.Sprintf("mjengo(herufi %d)", b.text.Len())
+}
+
+// Add appends obj. Text builders add strings as they are and any other
+// object the way andika prints it.
+func (b *Builder) Add(obj Object) {
+	if b.Array {
+		b.elements = append(b.elements, obj)
+		return
+	}
+	b.text.WriteString(obj.Inspect())
+}
+
+// Result returns what has been built so far. The builder can keep being
+// used afterwards without changing the returned value.
+func (b *Builder) Result() Object {
+	if b.Array {
+		elements := make([]Object, len(b.elements))
+		copy(elements, b.elements)
+		return &Array{Elements: elements}
+	}
+	return &String{Value: b.text.String()}
+}
+
+// Iterable interface for dicts, strings, arrays, sets and file lines
 type Iterable interface {
 	Next() (Object, Object)
 	Reset()
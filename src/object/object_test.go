@@ -1,6 +1,11 @@
 package object
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "Hello World"}
@@ -20,3 +25,251 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("Strings with different content have the same dict keys")
 	}
 }
+
+func TestDictInspectOrder(t *testing.T) {
+	dict := &Dict{}
+	for _, key := range []string{"zebra", "apple", "mango", "kiwi", "banana"} {
+		k := &String{Value: key}
+		dict.Set(k.HashKey(), DictPair{Key: k, Value: &Integer{Value: int64(len(key))}})
+	}
+	// updating an existing key keeps its original position
+	k := &String{Value: "apple"}
+	dict.Set(k.HashKey(), DictPair{Key: k, Value: &Integer{Value: 0}})
+
+	expected := "{zebra: 5, apple: 0, mango: 5, kiwi: 4, banana: 6}"
+	for i := 0; i < 10; i++ {
+		if dict.Inspect() != expected {
+			t.Fatalf("dict printed wrongly on call %d, got=%q, want=%q", i, dict.Inspect(), expected)
+		}
+	}
+}
+
+func TestDictInspectWithoutOrder(t *testing.T) {
+	one := &Integer{Value: 1}
+	two := &Integer{Value: 2}
+	three := &Integer{Value: 3}
+	dict := &Dict{Pairs: map[HashKey]DictPair{
+		three.HashKey(): {Key: three, Value: three},
+		one.HashKey():   {Key: one, Value: one},
+		two.HashKey():   {Key: two, Value: two},
+	}}
+
+	expected := "{1: 1, 2: 2, 3: 3}"
+	for i := 0; i < 10; i++ {
+		if dict.Inspect() != expected {
+			t.Fatalf("dict printed wrongly on call %d, got=%q, want=%q", i, dict.Inspect(), expected)
+		}
+	}
+}
+
+func TestLinesClosesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	if err := os.WriteFile(path, []byte("moja\nmbili\ntatu\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := OpenLines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stopping early, like a break, closes the file on Reset
+	lines.Next()
+	lines.Reset()
+	if lines.file != nil {
+		t.Errorf("file still open after Reset")
+	}
+
+	// iterating again starts from the first line
+	got := []string{}
+	for _, v := lines.Next(); v != nil; _, v = lines.Next() {
+		got = append(got, v.Inspect())
+	}
+	if strings.Join(got, ",") != "moja,mbili,tatu" {
+		t.Errorf("wrong lines. got=%v", got)
+	}
+	if lines.file != nil {
+		t.Errorf("file still open after the last line")
+	}
+}
+
+func TestOpenLinesMissingFile(t *testing.T) {
+	if _, err := OpenLines(filepath.Join(t.TempDir(), "hakuna.txt")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestStringIteratesCharacters(t *testing.T) {
+	tests := []struct {
+		input string
+		chars []string
+		sum   int
+	}{
+		{"habari", []string{"h", "a", "b", "a", "r", "i"}, 104 + 97 + 98 + 97 + 114 + 105},
+		{"café", []string{"c", "a", "f", "é"}, 99 + 97 + 102 + 233},
+		{"✓😀", []string{"✓", "😀"}, 0x2713 + 0x1F600},
+		{"", nil, 0},
+	}
+
+	for _, tt := range tests {
+		str := &String{Value: tt.input}
+		for pass := 0; pass < 2; pass++ {
+			var chars []string
+			sum := 0
+			for k, v := str.Next(); k != nil; k, v = str.Next() {
+				if k.(*Integer).Value != int64(len(chars)) {
+					t.Errorf("%q: character %d has index %d", tt.input, len(chars), k.(*Integer).Value)
+				}
+				char := v.(*String).Value
+				chars = append(chars, char)
+				for _, r := range char {
+					sum += int(r)
+				}
+			}
+			str.Reset()
+
+			if strings.Join(chars, ",") != strings.Join(tt.chars, ",") {
+				t.Errorf("%q: expected %v, got %v", tt.input, tt.chars, chars)
+			}
+			if sum != tt.sum {
+				t.Errorf("%q: expected character codes to add up to %d, got %d", tt.input, tt.sum, sum)
+			}
+		}
+	}
+}
+
+func TestSetKeepsFirstInsertion(t *testing.T) {
+	set := &Set{}
+	for _, value := range []string{"b", "a", "b", "c", "a"} {
+		set.Add(&String{Value: value})
+	}
+
+	if !set.Add(&Integer{Value: 1}) || set.Add(&Integer{Value: 1}) {
+		t.Errorf("Add should report whether the value was new")
+	}
+	if set.Inspect() != "seti{b, a, c, 1}" {
+		t.Errorf("wrong order or duplicates: %s", set.Inspect())
+	}
+	if !set.Has(&String{Value: "c"}) || set.Has(&String{Value: "d"}) {
+		t.Errorf("Has gave the wrong answer")
+	}
+	if set.Len() != 4 {
+		t.Errorf("expected 4 members, got %d", set.Len())
+	}
+}
+
+func TestSizedEnclosedEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	outer.Set("y", &Integer{Value: 2})
+
+	env := NewSizedEnclosedEnvironment(outer, 1)
+	env.Set("x", &Integer{Value: 10})
+
+	// enough variables to move past the slice into a map
+	for i := 0; i < maxSmallEnvironment+4; i++ {
+		env.Set(string(rune('a'+i)), &Integer{Value: int64(i)})
+		env.Set("x", &Integer{Value: int64(100 + i)})
+	}
+
+	if val, ok := env.Get("x"); !ok || val.(*Integer).Value != 100+maxSmallEnvironment+3 {
+		t.Errorf("x should be the latest value set, got=%v", val)
+	}
+	for i := 0; i < maxSmallEnvironment+4; i++ {
+		name := string(rune('a' + i))
+		if val, ok := env.Get(name); !ok || val.(*Integer).Value != int64(i) {
+			t.Errorf("%s should be %d, got=%v", name, i, val)
+		}
+	}
+	if val, ok := env.Get("y"); !ok || val.(*Integer).Value != 2 {
+		t.Errorf("y should come from the outer environment, got=%v", val)
+	}
+	if val, _ := outer.Get("x"); val.(*Integer).Value != 1 {
+		t.Errorf("the outer x should not change, got=%v", val)
+	}
+	if _, ok := env.Get("hakuna"); ok {
+		t.Errorf("an unknown name should not be found")
+	}
+}
+
+func TestErrorColor(t *testing.T) {
+	err := &Error{Message: "Mstari 1: kosa"}
+
+	if err.Inspect() != "\x1b[31mKosa: \x1b[0m\x1b[31mMstari 1: kosa\x1b[0m" {
+		t.Errorf("errors should be red by default, got=%q", err.Inspect())
+	}
+
+	SetColor(false)
+	defer SetColor(true)
+	if err.Inspect() != "Kosa: Mstari 1: kosa" {
+		t.Errorf("errors should have no color codes, got=%q", err.Inspect())
+	}
+	if Colorize("sawa", 32) != "sawa" {
+		t.Errorf("Colorize should leave the string alone, got=%q", Colorize("sawa", 32))
+	}
+}
+
+func TestErrorPlain(t *testing.T) {
+	err := &Error{Message: "Mstari 1: kosa", Trace: []string{"ndani ya f, mstari 2"}}
+
+	if err.Plain() != "Kosa: Mstari 1: kosa\n\tndani ya f, mstari 2" {
+		t.Errorf("Plain should have no color codes, got=%q", err.Plain())
+	}
+	if !strings.Contains(err.Inspect(), "\x1b[31m") {
+		t.Errorf("Inspect should still be colored, got=%q", err.Inspect())
+	}
+}
+
+func TestEnclosedEnvironmentsKeepBuiltins(t *testing.T) {
+	allowed := map[string]*Builtin{"andika": {}}
+	root := NewEnvironment()
+	root.SetBuiltins(allowed)
+
+	inner := NewSizedEnclosedEnvironment(NewEnclosedEnvironment(root), 2)
+	if len(inner.Builtins()) != 1 || inner.Builtins()["andika"] == nil {
+		t.Errorf("enclosed environments should keep the builtins of outer, got=%v", inner.Builtins())
+	}
+	if NewEnclosedEnvironment(NewEnvironment()).Builtins() != nil {
+		t.Errorf("without SetBuiltins every builtin should be allowed")
+	}
+}
+
+func TestDictNextTakesKeysOnce(t *testing.T) {
+	dict := &Dict{}
+	for i := 0; i < 100; i++ {
+		key := &Integer{Value: int64(i)}
+		dict.Set(key.HashKey(), DictPair{Key: key, Value: key})
+	}
+
+	count := 0
+	allocs := testing.AllocsPerRun(10, func() {
+		count = 0
+		for k, _ := dict.Next(); k != nil; k, _ = dict.Next() {
+			if k.(*Integer).Value != int64(count) {
+				t.Fatalf("wrong key %d, got=%s", count, k.Inspect())
+			}
+			count++
+		}
+		dict.Reset()
+	})
+	if count != 100 {
+		t.Errorf("expected 100 pairs, got=%d", count)
+	}
+	if allocs > 5 {
+		t.Errorf("looping over a dict should not allocate on every step, got=%v allocations", allocs)
+	}
+
+	// pairs added during a loop wait for the next one
+	added := &Integer{Value: 100}
+	count = 0
+	for k, _ := dict.Next(); k != nil; k, _ = dict.Next() {
+		if count == 0 {
+			dict.Set(added.HashKey(), DictPair{Key: added, Value: added})
+		}
+		count++
+	}
+	dict.Reset()
+	if count != 100 {
+		t.Errorf("expected 100 pairs in the loop, got=%d", count)
+	}
+}
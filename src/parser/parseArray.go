@@ -8,7 +8,67 @@ import (
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
-	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		array.Elements = []ast.Expression{}
+		return array
+	}
+
+	tok := p.curToken
+	p.nextToken()
+	first := p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.FOR) {
+		comp := &ast.Comprehension{Token: tok, Value: first}
+		return p.parseComprehension(comp, token.RBRACKET)
+	}
+
+	array.Elements = []ast.Expression{first}
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		array.Elements = append(array.Elements, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
 
 	return array
 }
+
+// parseComprehension parses the 'kwa x ktk orodha kama sharti' part of
+// a comprehension, starting with 'kwa' as the peek token
+func (p *Parser) parseComprehension(comp *ast.Comprehension, end token.TokenType) ast.Expression {
+	p.nextToken()
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	comp.ValueName = p.curToken.Literal
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		comp.KeyName = comp.ValueName
+		comp.ValueName = p.curToken.Literal
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+	p.nextToken()
+	comp.Iterable = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		p.nextToken()
+		comp.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(end) {
+		return nil
+	}
+
+	return comp
+}
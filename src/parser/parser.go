@@ -27,30 +27,40 @@ const (
 )
 
 var precedences = map[token.TokenType]int{
-	token.AND:             COND,
-	token.OR:              COND,
-	token.IN:              COND,
-	token.ASSIGN:          ASSIGN,
-	token.EQ:              EQUALS,
-	token.NOT_EQ:          EQUALS,
-	token.LT:              LESSGREATER,
-	token.LTE:             LESSGREATER,
-	token.GT:              LESSGREATER,
-	token.GTE:             LESSGREATER,
-	token.PLUS:            SUM,
-	token.PLUS_ASSIGN:     SUM,
-	token.MINUS:           SUM,
-	token.MINUS_ASSIGN:    SUM,
-	token.SLASH:           PRODUCT,
-	token.SLASH_ASSIGN:    PRODUCT,
-	token.ASTERISK:        PRODUCT,
-	token.ASTERISK_ASSIGN: PRODUCT,
-	token.POW:             POWER,
-	token.MODULUS:         MODULUS,
-	token.MODULUS_ASSIGN:  MODULUS,
+	token.AND:              COND,
+	token.OR:               COND,
+	token.IN:               COND,
+	token.NOT_IN:           COND,
+	token.ASSIGN:           ASSIGN,
+	token.EQ:               EQUALS,
+	token.NOT_EQ:           EQUALS,
+	token.LT:               LESSGREATER,
+	token.LTE:              LESSGREATER,
+	token.GT:               LESSGREATER,
+	token.GTE:              LESSGREATER,
+	token.PLUS:             SUM,
+	token.PLUS_ASSIGN:      SUM,
+	token.MINUS:            SUM,
+	token.MINUS_ASSIGN:     SUM,
+	token.SLASH:            PRODUCT,
+	token.SLASH_ASSIGN:     PRODUCT,
+	token.FLOOR_DIV:        PRODUCT,
+	token.FLOOR_DIV_ASSIGN: PRODUCT,
+	token.ASTERISK:         PRODUCT,
+	token.ASTERISK_ASSIGN:  PRODUCT,
+	token.POW:              POWER,
+	token.POW_ASSIGN:       POWER,
+	token.MODULUS:          MODULUS,
+	token.MODULUS_ASSIGN:   MODULUS,
+	token.AND_ASSIGN:       COND,
+	token.OR_ASSIGN:        COND,
 	// token.BANG:     PREFIX,
 	token.LPAREN:   CALL,
 	token.LBRACKET: INDEX, // Highest priority
+	token.DOT:      INDEX,
+
+	token.OPTIONAL_DOT:      INDEX,
+	token.OPTIONAL_LBRACKET: INDEX,
 }
 
 type (
@@ -99,6 +109,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.FOR, p.parseForExpression)
 	p.registerPrefix(token.SWITCH, p.parseSwitchStatement)
+	p.registerPrefix(token.BLOCK, p.parseBlockExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.AND, p.parseInfixExpression)
@@ -109,11 +120,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.FLOOR_DIV, p.parseInfixExpression)
+	p.registerInfix(token.FLOOR_DIV_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.POW_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.POW, p.parseInfixExpression)
 	p.registerInfix(token.MODULUS, p.parseInfixExpression)
 	p.registerInfix(token.MODULUS_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.AND_ASSIGN, p.parseAssignmentExpression)
+	p.registerInfix(token.OR_ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
@@ -124,6 +140,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.ASSIGN, p.parseAssignmentExpression)
 	p.registerInfix(token.IN, p.parseInfixExpression)
+	p.registerInfix(token.NOT_IN, p.parseInfixExpression)
+	p.registerInfix(token.DOT, p.parsePropertyExpression)
+	p.registerInfix(token.OPTIONAL_DOT, p.parsePropertyExpression)
+	p.registerInfix(token.OPTIONAL_LBRACKET, p.parseIndexExpression)
 
 	p.postfixParseFns = make(map[token.TokenType]postfixParseFn)
 	p.registerPostfix(token.PLUS_PLUS, p.parsePostfixExpression)
@@ -162,6 +182,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseBreak()
 	case token.CONTINUE:
 		return p.parseContinue()
+	case token.YIELD:
+		return p.parseYieldStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -197,7 +219,17 @@ func (p *Parser) parseNull() ast.Expression {
 
 func (p *Parser) parseAssignmentExpression(exp ast.Expression) ast.Expression {
 	switch node := exp.(type) {
-	case *ast.Identifier, *ast.IndexExpression:
+	case *ast.IndexExpression:
+		if node.Optional {
+			p.errors = append(p.errors, fmt.Sprintf("Mstari %d: Huwezi kuweka thamani kwenye ?[ ]", p.curToken.Line))
+			return nil
+		}
+	case *ast.SliceExpression:
+		if node.Optional {
+			p.errors = append(p.errors, fmt.Sprintf("Mstari %d: Huwezi kuweka thamani kwenye ?[ ]", p.curToken.Line))
+			return nil
+		}
+	case *ast.Identifier:
 	default:
 		if node != nil {
 			msg := fmt.Sprintf("Mstari %d:Tulitegemea kupata kitambulishi au array, badala yake tumepata: %s", p.curToken.Line, node.TokenLiteral())
@@ -237,7 +269,13 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) Errors() []string {
-	return p.errors
+	lexErrors := p.l.Errors()
+	if len(lexErrors) == 0 {
+		return p.errors
+	}
+	errors := make([]string, 0, len(lexErrors)+len(p.errors))
+	errors = append(errors, lexErrors...)
+	return append(errors, p.errors...)
 }
 
 func (p *Parser) peekError(t token.TokenType) {
@@ -440,6 +478,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 			expression.Alternative = &ast.BlockStatement{
 				Statements: []ast.Statement{
 					&ast.ExpressionStatement{
+						Token:      p.curToken,
 						Expression: p.parseIfExpression(),
 					},
 				},
@@ -524,10 +563,48 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
-	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if !p.parseCallArguments(exp) {
+		return nil
+	}
 	return exp
 }
 
+// parseCallArguments parses positional arguments followed by named ones,
+// eg salimu("habari", jina: "Asha", umri: 20)
+func (p *Parser) parseCallArguments(exp *ast.CallExpression) bool {
+	exp.Arguments = []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return true
+	}
+
+	for {
+		p.nextToken()
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.COLON) {
+			arg := &ast.NamedArgument{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+			p.nextToken()
+			p.nextToken()
+			arg.Value = p.parseExpression(LOWEST)
+			exp.Named = append(exp.Named, arg)
+		} else {
+			if len(exp.Named) > 0 {
+				msg := fmt.Sprintf("Mstari %d: Hoja za kawaida lazima zije kabla ya hoja zenye majina", p.curToken.Line)
+				p.errors = append(p.errors, msg)
+				return false
+			}
+			exp.Arguments = append(exp.Arguments, p.parseExpression(LOWEST))
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	return p.expectPeek(token.RPAREN)
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
@@ -557,9 +634,18 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	exp.Optional = p.curTokenIs(token.OPTIONAL_LBRACKET)
 
 	p.nextToken()
+	if p.curTokenIs(token.COLON) {
+		return p.parseSliceExpression(exp.Token, left, nil)
+	}
+
 	exp.Index = p.parseExpression(LOWEST)
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(exp.Token, left, exp.Index)
+	}
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
@@ -567,6 +653,44 @@ func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+// parseSliceExpression parses the rest of a[start:end] or a[start:end:step]
+// once the first ':' is the current token
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+	exp.Optional = tok.Type == token.OPTIONAL_LBRACKET
+
+	if !p.peekTokenIs(token.RBRACKET) && !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		exp.End = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parsePropertyExpression(obj ast.Expression) ast.Expression {
+	exp := &ast.PropertyExpression{Token: p.curToken, Object: obj}
+	exp.Optional = p.curTokenIs(token.OPTIONAL_DOT)
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
 func (p *Parser) parseDictLiteral() ast.Expression {
 	dict := &ast.DictLiteral{Token: p.curToken}
 	dict.Pairs = make(map[ast.Expression]ast.Expression)
@@ -582,7 +706,13 @@ func (p *Parser) parseDictLiteral() ast.Expression {
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 
+		if len(dict.Keys) == 0 && p.peekTokenIs(token.FOR) {
+			comp := &ast.Comprehension{Token: dict.Token, Key: key, Value: value}
+			return p.parseComprehension(comp, token.RBRACE)
+		}
+
 		dict.Pairs[key] = value
+		dict.Keys = append(dict.Keys, key)
 
 		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
 			return nil
@@ -619,6 +749,40 @@ func (p *Parser) parseWhileExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseBlockExpression() ast.Expression {
+	expression := &ast.BlockExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Block = p.parseBlockStatement()
+
+	return expression
+}
+
+// parseYieldStatement reads toa and the value after it, if there is one
+// before the end of the line or block
+func (p *Parser) parseYieldStatement() *ast.YieldStatement {
+	stmt := &ast.YieldStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+		return stmt
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseBreak() *ast.Break {
 	stmt := &ast.Break{Token: p.curToken}
 	for p.curTokenIs(token.SEMICOLON) {
@@ -638,6 +802,9 @@ func (p *Parser) parseContinue() *ast.Continue {
 func (p *Parser) parseForExpression() ast.Expression {
 	expression := &ast.For{Token: p.curToken}
 	p.nextToken()
+	if p.curTokenIs(token.LBRACKET) {
+		return p.parseForInExpression(expression)
+	}
 	if !p.curTokenIs(token.IDENT) {
 		return nil
 	}
@@ -692,6 +859,14 @@ func (p *Parser) parseForExpression() ast.Expression {
 
 func (p *Parser) parseForInExpression(initialExpression *ast.For) ast.Expression {
 	expression := &ast.ForIn{Token: initialExpression.Token}
+	if p.curTokenIs(token.LBRACKET) {
+		expression.Pattern = p.parseLoopPattern()
+		if expression.Pattern == nil {
+			return nil
+		}
+		p.nextToken()
+		return p.finishForInExpression(expression)
+	}
 	if !p.curTokenIs(token.IDENT) {
 		return nil
 	}
@@ -699,16 +874,48 @@ func (p *Parser) parseForInExpression(initialExpression *ast.For) ast.Expression
 	var key string
 	p.nextToken()
 	if p.curTokenIs(token.COMMA) {
+		key = val
 		p.nextToken()
-		if !p.curTokenIs(token.IDENT) {
+		if p.curTokenIs(token.LBRACKET) {
+			expression.Pattern = p.parseLoopPattern()
+			if expression.Pattern == nil {
+				return nil
+			}
+			val = ""
+		} else if p.curTokenIs(token.IDENT) {
+			val = p.curToken.Literal
+		} else {
 			return nil
 		}
-		key = val
-		val = p.curToken.Literal
 		p.nextToken()
 	}
 	expression.Key = key
 	expression.Value = val
+	return p.finishForInExpression(expression)
+}
+
+// parseLoopPattern parses the [a, b] of kwa [a, b] ktk jozi, leaving the
+// ']' as the current token
+func (p *Parser) parseLoopPattern() []string {
+	names := []string{}
+	for {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		names = append(names, p.curToken.Literal)
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return names
+}
+
+// finishForInExpression parses the ktk part of a for-in loop and its block
+func (p *Parser) finishForInExpression(expression *ast.ForIn) ast.Expression {
 	if !p.curTokenIs(token.IN) {
 		return nil
 	}
@@ -721,6 +928,17 @@ func (p *Parser) parseForInExpression(initialExpression *ast.For) ast.Expression
 	return expression
 }
 
+// comparisonOperators can start a case, eg ikiwa < 10, to compare the
+// switch value instead of checking it for equality
+var comparisonOperators = map[token.TokenType]bool{
+	token.LT:     true,
+	token.GT:     true,
+	token.LTE:    true,
+	token.GTE:    true,
+	token.EQ:     true,
+	token.NOT_EQ: true,
+}
+
 func (p *Parser) parseSwitchStatement() ast.Expression {
 	expression := &ast.SwitchExpression{Token: p.curToken}
 
@@ -763,6 +981,13 @@ func (p *Parser) parseSwitchStatement() ast.Expression {
 
 			if p.curTokenIs(token.DEFAULT) {
 				tmp.Default = true
+			} else if p.curTokenIs(token.IF) {
+				p.nextToken()
+				tmp.Guard = p.parseExpression(LOWEST)
+			} else if comparisonOperators[p.curToken.Type] {
+				tmp.Operator = p.curToken.Literal
+				p.nextToken()
+				tmp.Expr = append(tmp.Expr, p.parseExpression(LOWEST))
 			} else {
 				tmp.Expr = append(tmp.Expr, p.parseExpression(LOWEST))
 				for p.peekTokenIs(token.COMMA) {
@@ -316,6 +316,10 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"a + b / c",
 			"(a + (b / c))",
 		},
+		{
+			"a + b ~/ c * d",
+			"(a + ((b ~/ c) * d))",
+		},
 		{
 			"a + b * c + d / e - f",
 			"(((a + (b * c)) + (d / e)) - f)",
@@ -632,6 +636,42 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestElseIfChain(t *testing.T) {
+	input := `kama (x < 0) { a } au kama (x < 10) { b } sivyo { c }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+	if !testInfixExpression(t, exp.Condition, "x", "<", 0) {
+		return
+	}
+
+	if exp.Alternative == nil || len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("else if is not a single statement alternative. got=%v", exp.Alternative)
+	}
+	elseIf, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfExpression. got=%T", exp.Alternative.Statements[0])
+	}
+	if !testInfixExpression(t, elseIf.Condition, "x", "<", 10) {
+		return
+	}
+	if !testIdentifier(t, elseIf.Consequence.Statements[0].(*ast.ExpressionStatement).Expression, "b") {
+		return
+	}
+	if elseIf.Alternative == nil {
+		t.Fatalf("final sivyo block is missing")
+	}
+	testIdentifier(t, elseIf.Alternative.Statements[0].(*ast.ExpressionStatement).Expression, "c")
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `unda(x, y) {x + y}`
 
@@ -1061,3 +1101,260 @@ func TestForExpression(t *testing.T) {
 		t.Fatalf("Wrong Value Index, expected 'v' got %s", exp.Value)
 	}
 }
+
+func TestPropertyExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"mtu.jina", "(mtu.jina)"},
+		{"mtu.anwani.mji", "((mtu.anwani).mji)"},
+		{"mtu.salimu(1)", "(mtu.salimu)(1)"},
+		{"a.b + c.d", "((a.b) + (c.d))"},
+		{"a[0].b", "((a[0]).b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	l := lexer.New(`fanya x = "habari\q";`)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got=%v", errors)
+	}
+	if errors[0] != `Mstari 0: Alama ya kutoroka haijulikani: \q` {
+		t.Errorf("wrong error, got=%q", errors[0])
+	}
+}
+
+func TestSliceExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:3]", "(a[1:3])"},
+		{"a[:3]", "(a[:3])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:]", "(a[:])"},
+		{"a[i + 1:-1]", "(a[(i + 1):(-1)])"},
+		{"a[1:3] = b", "(a[1:3])=b"},
+		{"a[::2]", "(a[::2])"},
+		{"a[::-1]", "(a[::(-1)])"},
+		{"a[1:5:2]", "(a[1:5:2])"},
+		{"a[1::2]", "(a[1::2])"},
+		{"a[:5:]", "(a[:5])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestForInPatternParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"kwa [a, b] ktk jozi { a }", "kwa [a, b] ktk jozi {\n\ta\n}"},
+		{"kwa i, [a, b, c] ktk jozi { a }", "kwa i, [a, b, c] ktk jozi {\n\ta\n}"},
+		{"kwa [a] ktk jozi { a }", "kwa [a] ktk jozi {\n\ta\n}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	for _, input := range []string{"kwa [] ktk jozi { a }", "kwa [a, 1] ktk jozi { a }", "kwa [a, b ktk jozi { a }"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: expected a parse error", input)
+		}
+	}
+}
+
+func TestOptionalAccessParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a?.b", "(a?.b)"},
+		{"a?[0]", "(a?[0])"},
+		{"a?.b?.c", "((a?.b)?.c)"},
+		{"a?.b[0].c", "(((a?.b)[0]).c)"},
+		{"a?[1:2]", "(a?[1:2])"},
+		{"a.b", "(a.b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	for _, input := range []string{"a?[0] = 1", "a?[0:1] = [1]"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: expected a parse error", input)
+		}
+	}
+}
+
+func TestComprehensionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[x * 2 kwa x ktk orodha]", "[(x * 2) kwa x ktk orodha]"},
+		{"[x kwa x ktk orodha kama x > 0]", "[x kwa x ktk orodha kama (x > 0)]"},
+		{"{k: v kwa k, v ktk kamusi}", "{k: v kwa k, v ktk kamusi}"},
+		{"[1, 2, 3]", "[1, 2, 3]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestSwitchCaseForms(t *testing.T) {
+	input := `badili (x) { ikiwa 1, 2 { a } ikiwa < 10 { b } ikiwa kama x > y { c } kawaida { d } }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	switchExp, ok := stmt.Expression.(*ast.SwitchExpression)
+	if !ok {
+		t.Fatalf("expected *ast.SwitchExpression, got=%T", stmt.Expression)
+	}
+	if len(switchExp.Choices) != 4 {
+		t.Fatalf("expected 4 cases, got=%d", len(switchExp.Choices))
+	}
+
+	values, comparison, guard := switchExp.Choices[0], switchExp.Choices[1], switchExp.Choices[2]
+	if len(values.Expr) != 2 || values.Operator != "" || values.Guard != nil {
+		t.Errorf("wrong value case: %+v", values)
+	}
+	if comparison.Operator != "<" || comparison.Expr[0].String() != "10" {
+		t.Errorf("wrong comparison case: %+v", comparison)
+	}
+	if guard.Guard == nil || guard.Guard.String() != "(x > y)" {
+		t.Errorf("wrong guard case: %+v", guard)
+	}
+	if !switchExp.Choices[3].Default {
+		t.Errorf("expected the last case to be the default")
+	}
+}
+
+func TestNamedArgumentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`salimu(jina: "Asha", umri: 20)`, "salimu(jina: Asha, umri: 20)"},
+		{`salimu("habari", umri: 1 + 2)`, "salimu(habari, umri: (1 + 2))"},
+		{`salimu(a, b)`, "salimu(a, b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	p := New(lexer.New(`salimu(jina: "Asha", 20)`))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "Mstari 0: Hoja za kawaida lazima zije kabla ya hoja zenye majina" {
+		t.Errorf("expected a positional-after-named error, got=%v", p.Errors())
+	}
+}
+
+func TestBlockExpressionParsing(t *testing.T) {
+	l := lexer.New(`fanya x = kizuizi { fanya a = 1; toa a + 2 }`)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program has wrong number of statements. got=%d", len(program.Statements))
+	}
+	let, ok := program.Statements[0].(*ast.LetStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.LetStatement. got=%T", program.Statements[0])
+	}
+	block, ok := let.Value.(*ast.BlockExpression)
+	if !ok {
+		t.Fatalf("value is not ast.BlockExpression. got=%T", let.Value)
+	}
+	if len(block.Block.Statements) != 2 {
+		t.Fatalf("block has wrong number of statements. got=%d", len(block.Block.Statements))
+	}
+	yield, ok := block.Block.Statements[1].(*ast.YieldStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.YieldStatement. got=%T", block.Block.Statements[1])
+	}
+	if yield.Value.String() != "(a + 2)" {
+		t.Errorf("yield value wrong. got=%q", yield.Value.String())
+	}
+
+	p = New(lexer.New(`kizuizi { toa }`))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	block = program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.BlockExpression)
+	if yield := block.Block.Statements[0].(*ast.YieldStatement); yield.Value != nil {
+		t.Errorf("bare toa should have no value. got=%s", yield.Value.String())
+	}
+}
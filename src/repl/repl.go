@@ -2,6 +2,7 @@ package repl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -40,8 +41,27 @@ const ERROR_FACE = `
 
 `
 
+// EvalString runs src in env and returns the result. If src does not
+// parse, nothing is run and the parse errors are returned instead. Errors
+// raised while running come back as an *object.Error result, the same
+// way evaluator.Eval reports them.
+func EvalString(src string, env *object.Environment) (object.Object, []error) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		errs := make([]error, len(p.Errors()))
+		for i, msg := range p.Errors() {
+			errs[i] = errors.New(msg)
+		}
+		return nil, errs
+	}
+
+	return evaluator.Eval(evaluator.Fold(program), env), nil
+}
+
 func Read(contents string) {
-	env := object.NewEnvironment()
+	env := evaluator.NewEnvironment()
 
 	l := lexer.New(contents)
 	p := parser.New(l)
@@ -57,19 +77,21 @@ func Read(contents string) {
 		}
 
 	}
-	evaluated := evaluator.Eval(program, env)
-	if evaluated != nil {
-		if evaluated.Type() != object.NULL_OBJ {
-			fmt.Println(colorfy(evaluated.Inspect(), 32))
-		}
+	// scripts only print what they ask to, apart from errors
+	evaluated := evaluator.Eval(evaluator.Fold(program), env)
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		fmt.Println(evaluated.Inspect())
 	}
 
 }
 
 func Start(in io.Reader, out io.Writer) {
+	start(in, out, evaluator.NewEnvironment())
+}
 
+// start runs the REPL loop in env, which tests can fill in beforehand
+func start(in io.Reader, out io.Writer, env *object.Environment) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
 
 	for {
 		fmt.Print(PROMPT)
@@ -83,22 +105,43 @@ func Start(in io.Reader, out io.Writer) {
 			fmt.Println("✨🅺🅰🆁🅸🅱🆄 🆃🅴🅽🅰✨")
 			os.Exit(0)
 		}
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
+		evalLine(line, env, out)
+	}
+}
 
-		if len(p.Errors()) != 0 {
-			printParseErrors(out, p.Errors())
-			continue
-		}
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			if evaluated.Type() != object.NULL_OBJ {
-				io.WriteString(out, colorfy(evaluated.Inspect(), 32))
-				io.WriteString(out, "\n")
-			}
+// evalLine runs one line typed into the REPL and prints its results. A
+// trailing semicolon keeps the values from being printed, and a crash
+// inside the evaluator is reported instead of ending the session.
+func evalLine(line string, env *object.Environment, out io.Writer) {
+	defer func() {
+		if r := recover(); r != nil {
+			io.WriteString(out, colorfy(fmt.Sprintf("Samahani, kosa la ndani limetokea: %v", r), 31)+"\n")
 		}
+	}()
+
+	l := lexer.New(line)
+	p := parser.New(l)
+
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		printParseErrors(out, p.Errors())
+		return
+	}
+	evaluator.Fold(program)
+	show := func(obj object.Object) {
+		io.WriteString(out, colorfy(obj.Inspect(), 32))
+		io.WriteString(out, "\n")
+	}
+	quiet := strings.HasSuffix(strings.TrimSpace(line), ";")
+	var evaluated object.Object
+	if quiet {
+		evaluated = evaluator.Eval(program, env)
+	} else {
+		evaluated = evaluator.EvalInteractive(program, env, show)
+	}
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		io.WriteString(out, evaluated.Inspect()+"\n")
 	}
 }
 
@@ -112,5 +155,5 @@ func printParseErrors(out io.Writer, errors []string) {
 }
 
 func colorfy(str string, colorCode int) string {
-	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", colorCode, str)
+	return object.Colorize(str, colorCode)
 }
@@ -0,0 +1,174 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/AvicennaJr/Nuru/evaluator"
+	"github.com/AvicennaJr/Nuru/object"
+)
+
+func TestEvalString(t *testing.T) {
+	env := evaluator.NewEnvironment()
+
+	result, errs := EvalString("fanya x = 5; x * 2", env)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if integer, ok := result.(*object.Integer); !ok || integer.Value != 10 {
+		t.Errorf("expected 10, got=%+v", result)
+	}
+
+	// the environment is kept between calls
+	result, _ = EvalString("x + 1", env)
+	if integer, ok := result.(*object.Integer); !ok || integer.Value != 6 {
+		t.Errorf("expected 6, got=%+v", result)
+	}
+}
+
+func TestEvalStringTopLevelReturn(t *testing.T) {
+	result, errs := EvalString("fanya x = 40; rudisha x + 2; x", evaluator.NewEnvironment())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if integer, ok := result.(*object.Integer); !ok || integer.Value != 42 {
+		t.Errorf("expected 42, got=%+v", result)
+	}
+}
+
+func TestEvalStringSyntaxError(t *testing.T) {
+	env := evaluator.NewEnvironment()
+
+	result, errs := EvalString("fanya = 5; fanya y = 1", env)
+	if result != nil {
+		t.Errorf("expected no result for code that does not parse, got=%+v", result)
+	}
+	if len(errs) == 0 {
+		t.Fatalf("expected parse errors")
+	}
+	if errs[0].Error() != "Mstari 0: Tulitegemea kupata KITAMBULISHI, badala yake tumepata =" {
+		t.Errorf("wrong error message: %q", errs[0].Error())
+	}
+	if _, ok := env.Get("y"); ok {
+		t.Errorf("code ran despite parse errors")
+	}
+}
+
+func TestEvalStringRuntimeError(t *testing.T) {
+	result, errs := EvalString(`1 + "a"`, evaluator.NewEnvironment())
+	if len(errs) != 0 {
+		t.Fatalf("unexpected parse errors: %v", errs)
+	}
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected *object.Error, got=%T", result)
+	}
+	if errObj.Message != "Mstari 0: Aina Hazilingani: NAMBA + NENO" {
+		t.Errorf("wrong error message: %q", errObj.Message)
+	}
+}
+
+func TestStartPrintsExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 1\n", "\x1b[32m2\x1b[0m\n"},
+		{"1 + 1; 2 + 2\n", "\x1b[32m2\x1b[0m\n\x1b[32m4\x1b[0m\n"},
+		{"fanya x = 5\nx\n", "\x1b[32m5\x1b[0m\n"},
+		{"tupu\n", ""},
+		{"andika(3)\n", "3\n"},
+		{"1 + kweli; 2\n", "\x1b[31mKosa: \x1b[0m\x1b[31mMstari 0: Aina Hazilingani: NAMBA + BOOLEAN\x1b[0m\n"},
+		{"fanya f = unda() { 1 + kweli }; f()\n", "\x1b[31mKosa: \x1b[0m\x1b[31mMstari 0: Aina Hazilingani: NAMBA + BOOLEAN\x1b[0m\n\tndani ya f(), iliyoitwa Mstari 0\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		evaluator.SetOutput(&out)
+		Start(strings.NewReader(tt.input), &out)
+		evaluator.SetOutput(os.Stdout)
+
+		if out.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestEvalLineSurvivesPanic(t *testing.T) {
+	env := evaluator.NewEnvironment()
+	env.Set("vunjika", &object.Builtin{Fn: func(line int, args ...object.Object) object.Object {
+		panic("hitilafu")
+	}})
+
+	var out bytes.Buffer
+	evalLine("fanya x = 1; vunjika()", env, &out)
+	if out.String() != "\x1b[31mSamahani, kosa la ndani limetokea: hitilafu\x1b[0m\n" {
+		t.Errorf("wrong output after a panic: %q", out.String())
+	}
+
+	// the session carries on with the same environment
+	out.Reset()
+	evalLine("x + 1", env, &out)
+	if out.String() != "\x1b[32m2\x1b[0m\n" {
+		t.Errorf("expected the REPL to keep working, got=%q", out.String())
+	}
+}
+
+func TestStartSurvivesPanic(t *testing.T) {
+	env := evaluator.NewEnvironment()
+	env.Set("vunjika", &object.Builtin{Fn: func(line int, args ...object.Object) object.Object {
+		panic("hitilafu")
+	}})
+
+	var out bytes.Buffer
+	start(strings.NewReader("vunjika()\n1 + 1\n"), &out, env)
+
+	if !strings.HasPrefix(out.String(), "\x1b[31mSamahani, kosa la ndani limetokea: hitilafu") {
+		t.Errorf("expected the panic to be reported, got=%q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\x1b[32m2\x1b[0m\n") {
+		t.Errorf("expected the next line to run, got=%q", out.String())
+	}
+}
+
+func TestTrailingSemicolonSuppressesPrinting(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 1;\n", ""},
+		{"1 + 1; 2 + 2;\n", ""},
+		{"fanya x = 5;\nx\n", "\x1b[32m5\x1b[0m\n"},
+		{"1 + kweli;\n", "\x1b[31mKosa: \x1b[0m\x1b[31mMstari 0: Aina Hazilingani: NAMBA + BOOLEAN\x1b[0m\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out)
+
+		if out.String() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestReadPrintsErrorsInTheirOwnColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	Read("fanya x = 1\nx + kweli")
+	os.Stdout = stdout
+	w.Close()
+
+	var out bytes.Buffer
+	out.ReadFrom(r)
+	expected := "\x1b[31mKosa: \x1b[0m\x1b[31mMstari 1: Aina Hazilingani: NAMBA + BOOLEAN\x1b[0m\n"
+	if out.String() != expected {
+		t.Errorf("expected=%q, got=%q", expected, out.String())
+	}
+}
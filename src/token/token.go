@@ -19,29 +19,34 @@ const (
 	FLOAT  = "DESIMALI"
 
 	// Operators
-	ASSIGN          = "="
-	PLUS            = "+"
-	MINUS           = "-"
-	BANG            = "!"
-	ASTERISK        = "*"
-	POW             = "**"
-	SLASH           = "/"
-	MODULUS         = "%"
-	LT              = "<"
-	LTE             = "<="
-	GT              = ">"
-	GTE             = ">="
-	EQ              = "=="
-	NOT_EQ          = "!="
-	AND             = "&&"
-	OR              = "||"
-	PLUS_ASSIGN     = "+="
-	PLUS_PLUS       = "++"
-	MINUS_ASSIGN    = "-="
-	MINUS_MINUS     = "--"
-	ASTERISK_ASSIGN = "*="
-	SLASH_ASSIGN    = "/="
-	MODULUS_ASSIGN  = "%="
+	ASSIGN           = "="
+	PLUS             = "+"
+	MINUS            = "-"
+	BANG             = "!"
+	ASTERISK         = "*"
+	POW              = "**"
+	SLASH            = "/"
+	FLOOR_DIV        = "~/"
+	MODULUS          = "%"
+	LT               = "<"
+	LTE              = "<="
+	GT               = ">"
+	GTE              = ">="
+	EQ               = "=="
+	NOT_EQ           = "!="
+	AND              = "&&"
+	OR               = "||"
+	PLUS_ASSIGN      = "+="
+	PLUS_PLUS        = "++"
+	MINUS_ASSIGN     = "-="
+	MINUS_MINUS      = "--"
+	ASTERISK_ASSIGN  = "*="
+	SLASH_ASSIGN     = "/="
+	MODULUS_ASSIGN   = "%="
+	POW_ASSIGN       = "**="
+	FLOOR_DIV_ASSIGN = "~/="
+	AND_ASSIGN       = "&&="
+	OR_ASSIGN        = "||="
 
 	//Delimiters
 	COMMA     = ","
@@ -53,6 +58,12 @@ const (
 	LBRACKET  = "["
 	RBRACKET  = "]"
 	COLON     = ":"
+	DOT       = "."
+
+	// obj?.field and arr?[i] give tupu instead of an error when obj or
+	// arr is tupu
+	OPTIONAL_DOT      = "?."
+	OPTIONAL_LBRACKET = "?["
 
 	// Keywords
 	FUNCTION = "FUNCTION"
@@ -67,10 +78,13 @@ const (
 	BREAK    = "VUNJA"
 	CONTINUE = "ENDELEA"
 	IN       = "KTK"
+	NOT_IN   = "SI KTK"
 	FOR      = "KWA"
 	SWITCH   = "BADILI"
 	CASE     = "IKIWA"
 	DEFAULT  = "KAWAIDA"
+	BLOCK    = "KIZUIZI"
+	YIELD    = "TOA"
 )
 
 var keywords = map[string]TokenType{
@@ -91,6 +105,8 @@ var keywords = map[string]TokenType{
 	"badili":  SWITCH,
 	"ikiwa":   CASE,
 	"kawaida": DEFAULT,
+	"kizuizi": BLOCK,
+	"toa":     YIELD,
 }
 
 func LookupIdent(ident string) TokenType {